@@ -14,10 +14,13 @@ type SQLBuilder interface {
 	GetNoParseSql2Args() (string, []any)           // GetNoParseSql2Args 保留输入的占位符 SQL 语句和参数, spellsql 内部使用
 	GetSqlStr() string                             // GetSqlStr 解析输入占位符后的 SQL 语句, 建议用于打印日志(sql占位符替换为对应的值)
 	GetSql2Args() (string, []any)                  // GetSql2Args 根据不同数据库, 解析占位符后的 SQL 语句和参数, 用于执行 SQL 语句
+	Err() error                                    // Err 获取构建 SQL 时的错误, 如: dbType 没有注册
 }
 
 type Builder struct {
 	dbType     dialect.DbType
+	gd         dialect.Dialect // dbType 对应的方言, 没有注册时为 nil
+	err        error           // 构建时的错误, 如: dbType 没有注册
 	finalSql   strings.Builder
 	finalArgs  []any
 	genFinalFn func(b *Builder)
@@ -40,6 +43,7 @@ func (b *Builder) init(dt ...dialect.DbType) {
 	if len(dt) > 0 {
 		b.dbType = dt[0]
 	}
+	b.gd, b.err = dialect.GetDialect(b.dbType)
 }
 
 func (b *Builder) setGenFinal(f func(b *Builder)) {
@@ -143,15 +147,33 @@ func (b *Builder) GetNoParseSql2Args() (string, []any) {
 	return b.getFinalNoPraseSql2Args()
 }
 
+// GetDbType 获取数据库类型
+func (b *Builder) GetDbType() dialect.DbType {
+	return b.dbType
+}
+
+// Err 获取构建 SQL 时的错误, 如: dbType 没有注册
+func (b *Builder) Err() error {
+	return b.err
+}
+
 // GetSqlStr 解析输入占位符后的 SQL 语句, 建议用于打印日志
+// 注: Err 不为 nil 时返回空
 func (b *Builder) GetSqlStr() string {
+	if b.err != nil {
+		return ""
+	}
 	sqlStr, sqlArgs := b.getFinalNoPraseSql2Args()
 	// fmt.Println(sqlStr, sqlArgs)
 	return dialect.NewParsePlaceholder(b.dbType, sqlStr, sqlArgs...).Parse().Result()
 }
 
 // GetSql2Args 根据不同数据库, 解析占位符后的 SQL 语句和参数, 用于执行 SQL 语句
+// 注: Err 不为 nil 时返回空, 执行前需要通过 Err 判断
 func (b *Builder) GetSql2Args() (string, []any) {
+	if b.err != nil {
+		return "", nil
+	}
 	sqlStr, sqlArgs := b.getFinalNoPraseSql2Args()
 	// fmt.Println("======> before", sqlStr, sqlArgs)
 	pl := dialect.NewParsePlaceholder(b.dbType, sqlStr, sqlArgs...).Replace()
@@ -160,11 +182,10 @@ func (b *Builder) GetSql2Args() (string, []any) {
 }
 
 func (b *Builder) warpCol(col string) string {
-	gd := dialect.GetDialect(b.dbType)
-	if strings.HasPrefix(col, gd.GetWarpColSymbol()) {
+	if b.gd == nil { // dbType 没有注册, 错误通过 Err 返回
 		return col
 	}
-	return gd.GetWarpColSymbol() + col + gd.GetWarpColSymbol()
+	return dialect.WarpCol(b.gd, col)
 }
 
 func (b *Builder) warpJoinCols(fields ...string) string {
@@ -236,6 +236,26 @@ func TestInsert(t *testing.T) {
 		}
 	})
 
+	t.Run("postgres insert ignore", func(t *testing.T) {
+		i := NewInsert(dialect.Postgres)
+		i.IntoIgnore("user").Columns("name").Values("baz")
+		sql, _ := i.GetSql2Args()
+		expectedSql := "INSERT INTO user(\"name\") VALUES ($1) ON CONFLICT DO NOTHING"
+		if sql != expectedSql {
+			t.Errorf("sql error, got: %s, want: %s", sql, expectedSql)
+		}
+	})
+
+	t.Run("sqlite insert ignore", func(t *testing.T) {
+		i := NewInsert(dialect.SQLite)
+		i.IntoIgnore("user").Columns("name").Values("baz")
+		sql, _ := i.GetSql2Args()
+		expectedSql := "INSERT OR IGNORE INTO user(\"name\") VALUES (?)"
+		if sql != expectedSql {
+			t.Errorf("sql error, got: %s, want: %s", sql, expectedSql)
+		}
+	})
+
 	t.Run("insert init args no have values", func(t *testing.T) {
 		i := NewInsert(dialect.MySQL)
 		i.InitSql2Args("INSERT INTO user (name) VALUES")
@@ -329,6 +349,86 @@ func TestInsert(t *testing.T) {
 			t.Errorf("sql error, got: %s, want: %s", sql, expectedSql)
 		}
 	})
+
+	t.Run("sqlite insert", func(t *testing.T) {
+		i := NewInsert(dialect.SQLite)
+		i.Into("user").Columns("name", "age").Values("foo", internal.NULL)
+		sql, args := i.GetSql2Args()
+		expectedSql := "INSERT INTO user(\"name\", \"age\") VALUES (?, NULL)"
+		if sql != expectedSql {
+			t.Errorf("sql error, got: %s, want: %s", sql, expectedSql)
+		}
+		if len(args) != 1 || !test.Equal(args[0], "foo") {
+			t.Errorf("args error, got: %v", args)
+		}
+	})
+
+	t.Run("sqlite insert duplicate update", func(t *testing.T) {
+		i := NewInsert(dialect.SQLite)
+		i.Into("user").Columns("id", "name").
+			Values(1, "foo").
+			DuplicateUpdate([]string{"name"}, "id")
+
+		sql, _ := i.GetSql2Args()
+		expectedSql := "INSERT INTO user(\"id\", \"name\") VALUES (?, ?) ON CONFLICT (\"id\") DO UPDATE SET \"name\"=EXCLUDED.\"name\""
+		if sql != expectedSql {
+			t.Errorf("sql error, got: %s, want: %s", sql, expectedSql)
+		}
+	})
+
+	t.Run("postgres insert on conflict returning", func(t *testing.T) {
+		i := NewInsert(dialect.Postgres)
+		i.Into("user").Columns("id", "name").
+			Values(1, "foo").
+			OnConflict(`ON CONFLICT ("id") DO NOTHING`).
+			Returning("id")
+
+		sql, _ := i.GetSql2Args()
+		expectedSql := "INSERT INTO user(\"id\", \"name\") VALUES ($1, $2) ON CONFLICT (\"id\") DO NOTHING RETURNING \"id\""
+		if sql != expectedSql {
+			t.Errorf("sql error, got: %s, want: %s", sql, expectedSql)
+		}
+	})
+
+	t.Run("postgres insert returning", func(t *testing.T) {
+		i := NewInsert(dialect.Postgres)
+		i.Into("user").Columns("name").
+			Values("foo").
+			Values("bar").
+			Returning("id")
+
+		sql, _ := i.GetSql2Args()
+		expectedSql := "INSERT INTO user(\"name\") VALUES ($1), ($2) RETURNING \"id\""
+		if sql != expectedSql {
+			t.Errorf("sql error, got: %s, want: %s", sql, expectedSql)
+		}
+	})
+
+	t.Run("sqlserver insert output", func(t *testing.T) {
+		i := NewInsert(dialect.SQLServer)
+		i.Into("user").Columns("name").
+			Values("foo").
+			Returning("id")
+
+		sql, _ := i.GetSql2Args()
+		expectedSql := "INSERT INTO user([name]) OUTPUT INSERTED.[id] VALUES (@p1)"
+		if sql != expectedSql {
+			t.Errorf("sql error, got: %s, want: %s", sql, expectedSql)
+		}
+	})
+
+	t.Run("mysql insert returning ignore", func(t *testing.T) {
+		i := NewInsert(dialect.MySQL)
+		i.Into("user").Columns("name").
+			Values("foo").
+			Returning("id")
+
+		sql, _ := i.GetSql2Args()
+		expectedSql := "INSERT INTO user(`name`) VALUES (?)"
+		if sql != expectedSql {
+			t.Errorf("sql error, got: %s, want: %s", sql, expectedSql)
+		}
+	})
 }
 
 func TestDelete(t *testing.T) {
@@ -434,6 +534,23 @@ func TestUpdate(t *testing.T) {
 		}
 	})
 
+	t.Run("mysql set expr", func(t *testing.T) {
+		u := NewUpdate(dialect.MySQL)
+		u.Table("sys_user").
+			Set("username", "tao").
+			SetExpr("version", "version + ?", 1).
+			SetWhere(u.Where().Eq("id", 1).Eq("version", 2))
+
+		sql, args := u.GetSql2Args()
+		expectedSql := "UPDATE sys_user SET `username` = ?, `version` = version + ? WHERE `id` = ? AND `version` = ?"
+		if sql != expectedSql {
+			t.Errorf("sql error, got: %s, want: %s", sql, expectedSql)
+		}
+		if len(args) != 4 || !test.Equal(args[1], 1) || !test.Equal(args[3], 2) {
+			t.Errorf("args error, got: %v", args)
+		}
+	})
+
 	t.Run("postgres append", func(t *testing.T) {
 		u := NewUpdate(dialect.Postgres)
 		u.Table("sys_user").
@@ -529,6 +646,29 @@ func TestSelect(t *testing.T) {
 		}
 	})
 
+	t.Run("sqlserver offset fetch and quote", func(t *testing.T) {
+		s := NewSelect(dialect.SQLServer)
+		s.Select("id", "name").
+			From("users").
+			SetWhere(s.Where().Eq("id", 100).OrEq("name", "tao")).
+			Limit(2, 10)
+
+		sql, args := s.GetSql2Args()
+		if sql != "SELECT [id], [name] FROM users WHERE [id] = @p1 OR [name] = @p2 ORDER BY (SELECT NULL) OFFSET 10 ROWS FETCH NEXT 10 ROWS ONLY" {
+			t.Errorf("sqlserver select error: %s", sql)
+		}
+		if len(args) != 2 {
+			t.Errorf("args len error: %d", len(args))
+		}
+
+		s = NewSelect(dialect.SQLServer)
+		s.Select("id").From("users").OrderByDesc("id").Limit(1, 10)
+		sql = s.GetSqlStr()
+		if sql != "SELECT [id] FROM users ORDER BY [id] DESC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY" {
+			t.Errorf("sqlserver order by error: %s", sql)
+		}
+	})
+
 	t.Run("GetSqlStr validation", func(t *testing.T) {
 		s := NewSelect(dialect.MySQL)
 		s.Select("name").From("users").WhereCb(func(wb *Where) {
@@ -662,3 +802,24 @@ func TestSelect(t *testing.T) {
 		}
 	})
 }
+
+func TestUnknownDbType(t *testing.T) {
+	dt := dialect.DbType(200)
+	builders := map[string]SQLBuilder{
+		"select": NewSelect(dt).Select("id").From("man").WhereCb(func(wb *Where) { wb.Eq("id", 1) }).Limit(1, 10),
+		"insert": NewInsert(dt).Into("man").Columns("id", "name").Values(1, "test"),
+		"update": NewUpdate(dt).Table("man").Set("name", "test").WhereCb(func(wb *Where) { wb.Eq("id", 1) }),
+		"delete": NewDelete(dt).From("man").WhereCb(func(wb *Where) { wb.Eq("id", 1) }),
+	}
+	for name, b := range builders {
+		if b.Err() == nil {
+			t.Errorf("%s should be failed", name)
+		}
+		if sqlStr := b.GetSqlStr(); sqlStr != "" {
+			t.Errorf("%s sqlStr should be empty, got: %s", name, sqlStr)
+		}
+		if sqlStr, args := b.GetSql2Args(); sqlStr != "" || args != nil {
+			t.Errorf("%s sql2Args should be empty, got: %s, %v", name, sqlStr, args)
+		}
+	}
+}
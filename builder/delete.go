@@ -26,6 +26,10 @@ func (d *Delete) From(tableName string) *Delete {
 	return d
 }
 
+func (d *Delete) GetTableName() string {
+	return d.tableName
+}
+
 func (d *Delete) Where() *Where {
 	return d.where
 }
@@ -15,6 +15,8 @@ type Insert struct {
 	values      [][]any
 	conflictCol string
 	duplicate   []string // ON DUPLICATE KEY UPDATE
+	onConflict  string   // 冲突时的处理语句, 优先于 duplicate
+	returning   []string // RETURNING
 }
 
 func NewInsert(dt ...dialect.DbType) *Insert {
@@ -81,15 +83,36 @@ func (i *Insert) DuplicateUpdate(cols []string, conflictCol ...string) *Insert {
 	return i
 }
 
+// OnConflict 设置冲突时的处理语句, 会原样追加在 VALUES 后, 优先于 DuplicateUpdate
+// 一般通过 dialect.UpsertSql 生成, 如: OnConflict(`ON CONFLICT ("id") DO NOTHING`)
+func (i *Insert) OnConflict(sqlStr string) *Insert {
+	i.onConflict = sqlStr
+	return i
+}
+
+// Returning 设置插入后需要返回的字段, 如: 自增主键
+// 注: 仅 Postgres/SQLite(RETURNING) 和 SQLServer(OUTPUT INSERTED) 支持, 其他数据库会忽略
+func (i *Insert) Returning(cols ...string) *Insert {
+	if i.returning == nil {
+		i.returning = make([]string, 0, len(cols))
+	}
+	i.returning = append(i.returning, cols...)
+	return i
+}
+
 func (i *Insert) mergeSQL(b *Builder) {
 	if i.insertType != internal.None {
-		if i.insertType == internal.INSERT_REPLACE {
+		switch {
+		case i.insertType == internal.INSERT_REPLACE:
 			b.writeSql("REPLACE ")
-		} else {
+		case i.insertType == internal.INSERT_IGNORE && i.dbType == dialect.SQLite:
+			b.writeSql("INSERT OR IGNORE ")
+		case i.insertType == internal.INSERT_IGNORE && i.dbType == dialect.Postgres: // 在后面追加 ON CONFLICT DO NOTHING
+			b.writeSql("INSERT ")
+		case i.insertType == internal.INSERT_IGNORE:
+			b.writeSql("INSERT IGNORE ")
+		default:
 			b.writeSql("INSERT ")
-		}
-		if i.insertType == internal.INSERT_IGNORE {
-			b.writeSql("IGNORE ")
 		}
 		b.writeSql("INTO " + i.tableName)
 	}
@@ -98,6 +121,17 @@ func (i *Insert) mergeSQL(b *Builder) {
 		b.writeSql("(" + i.warpJoinCols(i.columns...) + ")")
 	}
 
+	// sqlserver 的 OUTPUT 需要在 VALUES 前面
+	if len(i.returning) > 0 && i.dbType == dialect.SQLServer {
+		b.writeSql(" OUTPUT ")
+		for index, col := range i.returning {
+			if index > 0 {
+				b.writeSql(", ")
+			}
+			b.writeSql("INSERTED." + i.warpCol(col))
+		}
+	}
+
 	if len(i.values) > 0 {
 		if ii := i.index(" VALUES"); ii == -1 {
 			b.writeSql(" VALUES ")
@@ -125,9 +159,13 @@ func (i *Insert) mergeSQL(b *Builder) {
 			b.writeSql(")")
 		}
 	}
-	if len(i.duplicate) > 0 {
+	if i.onConflict != "" {
+		b.writeSql(" " + i.onConflict)
+	} else if i.insertType == internal.INSERT_IGNORE && i.dbType == dialect.Postgres {
+		b.writeSql(" ON CONFLICT DO NOTHING")
+	} else if len(i.duplicate) > 0 {
 		switch i.dbType {
-		case dialect.Postgres:
+		case dialect.Postgres, dialect.SQLite:
 			b.writeSql(" ON CONFLICT (" + i.warpCol(i.conflictCol) + ") DO UPDATE SET ")
 			for index, col := range i.duplicate {
 				if index > 0 {
@@ -147,4 +185,11 @@ func (i *Insert) mergeSQL(b *Builder) {
 			}
 		}
 	}
+
+	if len(i.returning) > 0 {
+		switch i.dbType {
+		case dialect.Postgres, dialect.SQLite:
+			b.writeSql(" RETURNING " + i.warpJoinCols(i.returning...))
+		}
+	}
 }
@@ -281,8 +281,13 @@ func (s *Select) mergeSQL(b *Builder) {
 		b.writeSql(strings.Join(s.orderBys, ", "))
 	}
 
-	if s.limit > 0 {
+	if s.limit > 0 && s.gd != nil { // dbType 没有注册时, 错误通过 Err 返回
+		// 如: sqlserver 分页时必须有 ORDER BY
+		if lo, ok := s.gd.(dialect.LimitOrderByer); ok && len(s.orderBys) == 0 && !s.HaveStr(" ORDER BY") {
+			b.writeSql(" ")
+			b.writeSql(lo.GetLimitDefaultOrderBy())
+		}
 		b.writeSql(" ")
-		b.writeSql(dialect.GetDialect(s.dbType).GetLimitSql(s.limit, s.offset))
+		b.writeSql(s.gd.GetLimitSql(s.limit, s.offset))
 	}
 }
@@ -4,6 +4,12 @@ import "gitee.com/xuesongtao/spellsql/v2/dialect"
 
 var _ SQLBuilder = (*Update)(nil)
 
+// setExpr SetExpr 设置的表达式
+type setExpr struct {
+	expr string
+	args []any
+}
+
 type Update struct {
 	*Builder
 	tableName string
@@ -38,6 +44,12 @@ func (u *Update) Set(col string, value any) *Update {
 	return u
 }
 
+// SetExpr 以表达式设置列, 如: SetExpr("version", "version + ?", 1) => `version` = version + 1
+// 注: expr 会原样输出, 不要拼接外部输入
+func (u *Update) SetExpr(col string, expr string, args ...any) *Update {
+	return u.Set(col, setExpr{expr: expr, args: args})
+}
+
 func (u *Update) Where() *Where {
 	return u.where
 }
@@ -71,6 +83,10 @@ func (u *Update) mergeSQL(b *Builder) {
 			if i > 0 {
 				b.writeSql(", ")
 			}
+			if v, ok := u.values[i].(setExpr); ok {
+				b.writeSql2Args(u.warpCol(col)+" = "+v.expr, v.args...)
+				continue
+			}
 			b.writeSql2Args(u.warpCol(col)+" = "+dialect.Placeholders(), u.values[i])
 		}
 	}
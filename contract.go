@@ -28,6 +28,18 @@ const (
 	NULL = internal.NULL
 )
 
+// mysql innodb_autoinc_lock_mode, 用于 Insert 后回填自增主键
+// 单行插入时都能回填; 批量插入时只有 0, 1 模式下同一条语句分配的自增 id 是连续的, 才能通过 LastInsertId + 行偏移量回填
+// 注: 回填还需要 auto_increment_increment = 1
+const (
+	AutoIncLockModeTraditional uint8 = iota // 0
+	AutoIncLockModeConsecutive              // 1, mysql 8.0 之前的默认值
+	AutoIncLockModeInterleaved              // 2, mysql 8.0 的默认值, 批量插入不会回填
+)
+
+// ErrStaleObject 乐观锁更新失败, 即: 对象已被其他人修改, 见 Table.Version
+var ErrStaleObject = internal.StaleObjectErr
+
 // DBer
 type DBer = dialect.DBer
 
@@ -67,7 +79,12 @@ func (a *AfterHook) GetCall() string {
 }
 
 func defaultAfterHook(ctx context.Context, ah *AfterHook) {
-	sLog.Info(ctx, "("+ah.GetCall()+")", "[cost: "+fmt.Sprintf("%.3f", float64(time.Since(ah.St).Nanoseconds())/1e6)+"ms]", ah.Builder.GetSqlStr())
+	logAfterHook(sLog, ctx, ah)
+}
+
+// logAfterHook 打印执行的 sql 和耗时
+func logAfterHook(logger Logger, ctx context.Context, ah *AfterHook) {
+	logger.Info(ctx, "("+ah.GetCall()+")", "[cost: "+fmt.Sprintf("%.3f", float64(time.Since(ah.St).Nanoseconds())/1e6)+"ms]", ah.Builder.GetSqlStr())
 }
 
 func getCallInfo(skip int) []string {
@@ -2,6 +2,8 @@ package dialect
 
 import (
 	"database/sql"
+	"strconv"
+	"strings"
 )
 
 type DbType int // db 类型
@@ -10,14 +12,28 @@ func (d DbType) Is(dt DbType) bool {
 	return d == dt
 }
 
+// String 返回注册时的数据库名
+func (d DbType) String() string {
+	registerMu.RLock()
+	defer registerMu.RUnlock()
+	if name, ok := dbType2NameMap[d]; ok {
+		return name
+	}
+	return "unknown(" + strconv.Itoa(int(d)) + ")"
+}
+
 const (
 	PriFlag     = "PRI" // 主键标识
 	NotNullFlag = "NO"  // 非空标识
+
+	AutoIncrementFlag = "auto_increment" // 自增标识
 )
 
 const (
 	MySQL DbType = iota
 	Postgres
+	SQLite
+	SQLServer
 )
 
 var DefaultDbType = MySQL
@@ -25,9 +41,20 @@ var DefaultDbType = MySQL
 var (
 	_ Dialect = &MysqlTable{}
 	_ Dialect = &PgTable{}
+	_ Dialect = &SqliteTable{}
+	_ Dialect = &SqlServerTable{}
+
+	_ ColWarper      = &SqlServerTable{}
+	_ LimitOrderByer = &SqlServerTable{}
+	_ Savepointer    = &SqlServerTable{}
+	_ Upserter       = &MysqlTable{}
+	_ Upserter       = &PgTable{}
+	_ Upserter       = &SqliteTable{}
 
 	_ TableMeter = &MysqlTable{}
 	_ TableMeter = &PgTable{}
+	_ TableMeter = &SqliteTable{}
+	_ TableMeter = &SqlServerTable{}
 )
 
 // TableColInfo 表列详情
@@ -46,6 +73,11 @@ func (t *TableColInfo) IsPri() bool {
 	return t.Key == PriFlag
 }
 
+// IsAutoIncrement 是否为自增字段
+func (t *TableColInfo) IsAutoIncrement() bool {
+	return strings.Contains(strings.ToLower(t.Extra), AutoIncrementFlag)
+}
+
 // NotNull 数据库字段非空约束, NO 不能为 NULL, YES 能为 NULL
 func (t *TableColInfo) NotNull() bool {
 	return t.Null == NotNullFlag
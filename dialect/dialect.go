@@ -3,7 +3,10 @@ package dialect
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
 
 	"gitee.com/xuesongtao/spellsql/v2/internal"
 )
@@ -29,17 +32,114 @@ type TableMeter interface {
 	GetDefaultVal(col string, colInfo *TableColInfo) internal.RawSql
 }
 
+// ColWarper 字段左右包裹符号不一致时需要实现, 如: sqlserver 的 [col]
+type ColWarper interface {
+	GetWarpColSymbols() (left, right string)
+}
+
+// LimitOrderByer 分页时必须有 ORDER BY 的方言需要实现, 如: sqlserver 的 OFFSET/FETCH
+type LimitOrderByer interface {
+	GetLimitDefaultOrderBy() string // 没有 ORDER BY 时默认追加的 ORDER BY 语句
+}
+
+// Savepointer 事务保存点语法不一致时需要实现, 如: sqlserver
+type Savepointer interface {
+	GetSavepointSql(name string) string           // 创建保存点
+	GetRollbackToSavepointSql(name string) string // 回滚到保存点
+	GetReleaseSavepointSql(name string) string    // 释放保存点, 为空时表示不需要释放
+}
+
 var (
+	registerMu sync.RWMutex
 	dialectMap = map[DbType]Dialect{
-		MySQL:    Mysql(),
-		Postgres: Pg(),
+		MySQL:     Mysql(),
+		Postgres:  Pg(),
+		SQLite:    Sqlite(),
+		SQLServer: SqlServer(),
 	}
 	tableMeterMap = map[DbType]func() TableMeter{
-		MySQL:    func() TableMeter { return Mysql() },
-		Postgres: func() TableMeter { return Pg() },
+		MySQL:     func() TableMeter { return Mysql() },
+		Postgres:  func() TableMeter { return Pg() },
+		SQLite:    func() TableMeter { return Sqlite() },
+		SQLServer: func() TableMeter { return SqlServer() },
+	}
+	dbType2NameMap = map[DbType]string{
+		MySQL:     "mysql",
+		Postgres:  "postgres",
+		SQLite:    "sqlite",
+		SQLServer: "sqlserver",
+	}
+	name2DbTypeMap = map[string]DbType{
+		"mysql":     MySQL,
+		"postgres":  Postgres,
+		"sqlite":    SQLite,
+		"sqlserver": SQLServer,
 	}
 )
 
+// RegisterDialect 注册数据库方言, 便于外部适配 TiDB/MariaDB/SQL Server 等数据库, 无需修改源码
+// dt: 数据库类型, 自定义时建议从 100 开始, 防止与内置的冲突; 如果 dt 已注册, 会覆盖之前的注册(可用于自定义内置方言, 如: 修改 pg 的 schema)
+// name: 数据库名, 同一个 name 只能对应一个 dt, 可以通过 GetDbType 根据 name 获取 dt
+// d: 数据库方言
+// meter: 表元信息获取方法
+func RegisterDialect(dt DbType, name string, d Dialect, meter func() TableMeter) error {
+	if name == "" {
+		return errors.New("dialect name is required")
+	}
+	if d == nil {
+		return fmt.Errorf("dialect %q is nil", name)
+	}
+	if meter == nil {
+		return fmt.Errorf("dialect %q table meter is nil", name)
+	}
+
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	if old, ok := name2DbTypeMap[name]; ok && old != dt {
+		return fmt.Errorf("dialect %q is already registered by db type %d", name, old)
+	}
+	if oldName, ok := dbType2NameMap[dt]; ok && oldName != name {
+		delete(name2DbTypeMap, oldName)
+	}
+	dialectMap[dt] = d
+	tableMeterMap[dt] = meter
+	dbType2NameMap[dt] = name
+	name2DbTypeMap[name] = dt
+	return nil
+}
+
+// GetDbType 根据注册的数据库名获取数据库类型
+func GetDbType(name string) (DbType, error) {
+	registerMu.RLock()
+	defer registerMu.RUnlock()
+	dt, ok := name2DbTypeMap[name]
+	if !ok {
+		return 0, fmt.Errorf("dialect %q is not registered", name)
+	}
+	return dt, nil
+}
+
+// WarpCol 使用方言的包裹符号包裹字段, 已包裹的字段不会重复处理
+func WarpCol(d Dialect, col string) string {
+	left, right := d.GetWarpColSymbol(), d.GetWarpColSymbol()
+	if w, ok := d.(ColWarper); ok {
+		left, right = w.GetWarpColSymbols()
+	}
+	if strings.HasPrefix(col, left) {
+		return col
+	}
+	return left + col + right
+}
+
+// SavepointSqls 获取事务保存点相关 sql, 依次为: 创建, 回滚, 释放
+// 如果方言没有实现 Savepointer, 使用标准的 SAVEPOINT 语法
+func SavepointSqls(d Dialect, name string) (save, rollback, release string) {
+	if s, ok := d.(Savepointer); ok {
+		return s.GetSavepointSql(name), s.GetRollbackToSavepointSql(name), s.GetReleaseSavepointSql(name)
+	}
+	return "SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name, "RELEASE SAVEPOINT " + name
+}
+
 func WarpValue(d Dialect, value string) string {
 	if strings.HasPrefix(value, d.GetWarpValueStrSymbol()) {
 		return value
@@ -47,20 +147,40 @@ func WarpValue(d Dialect, value string) string {
 	return d.GetWarpValueStrSymbol() + value + d.GetWarpValueStrSymbol()
 }
 
-func GetTableMeter(dbType DbType) TableMeter {
+// GetTableMeter 获取表元信息, 如果 dbType 没有注册会返回错误
+func GetTableMeter(dbType DbType) (TableMeter, error) {
+	registerMu.RLock()
 	fn, ok := tableMeterMap[dbType]
-	if ok {
-		return fn()
+	registerMu.RUnlock()
+	if !ok {
+		return nil, unknownDbTypeErr(dbType)
 	}
-	return tableMeterMap[DefaultDbType]()
+	return fn(), nil
 }
 
-func GetDialect(dbType DbType) Dialect {
+// GetDialect 获取数据库方言, 如果 dbType 没有注册会返回错误
+func GetDialect(dbType DbType) (Dialect, error) {
+	registerMu.RLock()
 	dialect, ok := dialectMap[dbType]
-	if ok {
-		return dialect
+	registerMu.RUnlock()
+	if !ok {
+		return nil, unknownDbTypeErr(dbType)
 	}
-	return dialectMap[DefaultDbType]
+	return dialect, nil
+}
+
+// MustGetDialect 获取数据库方言, 如果 dbType 没有注册会 panic
+// 注: 仅用于初始化时等确定已注册的场景, 运行时应使用 GetDialect 并处理错误
+func MustGetDialect(dbType DbType) Dialect {
+	dialect, err := GetDialect(dbType)
+	if err != nil {
+		panic(err)
+	}
+	return dialect
+}
+
+func unknownDbTypeErr(dbType DbType) error {
+	return fmt.Errorf("db type %d is unknown, you should call RegisterDialect first", dbType)
 }
 
 func Placeholders(n ...int) string {
@@ -50,4 +50,142 @@ func TestParsePlaceholder(t *testing.T) {
 			t.Error("mysql replace error, result:", replaceStr)
 		}
 	})
+	t.Run("sqlite", func(t *testing.T) {
+		sqlStr := "SELECT * FROM user WHERE id=? AND name=?"
+		args := []any{1, "it's"}
+		p := NewParsePlaceholder(SQLite, sqlStr, args...)
+		parseStr := p.Parse().Result()
+		if parseStr != "SELECT * FROM user WHERE id=1 AND name='it''s'" {
+			t.Error("sqlite parse error, result:", parseStr)
+		}
+
+		replaceStr := p.Replace().Result()
+		if replaceStr != "SELECT * FROM user WHERE id=? AND name=?" {
+			t.Error("sqlite replace error, result:", replaceStr)
+		}
+	})
+	t.Run("sqlserver", func(t *testing.T) {
+		sqlStr := "SELECT * FROM user WHERE id=? AND name=?"
+		args := []any{1, "it's"}
+		p := NewParsePlaceholder(SQLServer, sqlStr, args...)
+		parseStr := p.Parse().Result()
+		if parseStr != "SELECT * FROM user WHERE id=1 AND name='it''s'" {
+			t.Error("sqlserver parse error, result:", parseStr)
+		}
+
+		replaceStr := p.Replace().Result()
+		if replaceStr != "SELECT * FROM user WHERE id=@p1 AND name=@p2" {
+			t.Error("sqlserver replace error, result:", replaceStr)
+		}
+	})
+}
+
+func TestRegisterDialect(t *testing.T) {
+	const tidb DbType = 100
+	t.Run("register", func(t *testing.T) {
+		if err := RegisterDialect(tidb, "tidb", Mysql(), func() TableMeter { return Mysql() }); err != nil {
+			t.Fatal(err)
+		}
+
+		dt, err := GetDbType("tidb")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dt != tidb || dt.String() != "tidb" {
+			t.Error("get db type error, result:", dt)
+		}
+
+		if _, err := GetDialect(tidb); err != nil {
+			t.Error(err)
+		}
+		if _, err := GetTableMeter(tidb); err != nil {
+			t.Error(err)
+		}
+
+		parseStr := NewParsePlaceholder(tidb, "SELECT * FROM user WHERE name=?", "test").Parse().Result()
+		if parseStr != "SELECT * FROM user WHERE name=\"test\"" {
+			t.Error("tidb parse error, result:", parseStr)
+		}
+	})
+
+	t.Run("name conflict", func(t *testing.T) {
+		if err := RegisterDialect(tidb+1, "mysql", Mysql(), func() TableMeter { return Mysql() }); err == nil {
+			t.Error("it should be name conflict")
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := GetDialect(tidb + 2); err == nil {
+			t.Error("it should be unknown db type")
+		}
+		if _, err := GetTableMeter(tidb + 2); err == nil {
+			t.Error("it should be unknown db type")
+		}
+		if _, err := GetDbType("unknown"); err == nil {
+			t.Error("it should be unknown name")
+		}
+
+		pl := NewParsePlaceholder(tidb+2, "SELECT * FROM user WHERE name=?", "test").Parse()
+		if pl.Err() == nil || pl.Result() != "SELECT * FROM user WHERE name=?" {
+			t.Error("unknown db type should not parse, result:", pl.Result())
+		}
+	})
+}
+
+func TestSavepointSqls(t *testing.T) {
+	save, rollback, release := SavepointSqls(Mysql(), "sp_1")
+	if save != "SAVEPOINT sp_1" || rollback != "ROLLBACK TO SAVEPOINT sp_1" || release != "RELEASE SAVEPOINT sp_1" {
+		t.Error("mysql savepoint error, result:", save, rollback, release)
+	}
+
+	save, rollback, release = SavepointSqls(SqlServer(), "sp_1")
+	if save != "SAVE TRANSACTION sp_1" || rollback != "ROLLBACK TRANSACTION sp_1" || release != "" {
+		t.Error("sqlserver savepoint error, result:", save, rollback, release)
+	}
+}
+
+func TestUpsertSql(t *testing.T) {
+	clause := &UpsertClause{ConflictCols: []string{"id"}, UpdateCols: []string{"name", "age"}, UpdateExprs: map[string]string{"num": "num + 1"}}
+	tests := []struct {
+		name   string
+		d      Dialect
+		clause *UpsertClause
+		want   string
+	}{
+		{"mysql", Mysql(), clause, "ON DUPLICATE KEY UPDATE `name`=VALUES(`name`), `age`=VALUES(`age`), `num`=num + 1"},
+		{"mysql do nothing", Mysql(), &UpsertClause{ConflictCols: []string{"id"}, DoNothing: true}, "ON DUPLICATE KEY UPDATE `id`=`id`"},
+		{"pg", Pg(), clause, `ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name", "age"=EXCLUDED."age", "num"=num + 1`},
+		{"pg multi conflict", Pg(), &UpsertClause{ConflictCols: []string{"a", "b"}, UpdateCols: []string{"c"}}, `ON CONFLICT ("a", "b") DO UPDATE SET "c"=EXCLUDED."c"`},
+		{"sqlite do nothing", Sqlite(), &UpsertClause{DoNothing: true}, "ON CONFLICT DO NOTHING"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UpsertSql(tt.d, tt.clause)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got: %s, want: %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := UpsertSql(Pg(), &UpsertClause{UpdateCols: []string{"c"}}); err == nil {
+		t.Error("pg conflict cols is empty, it should return err")
+	}
+	if _, err := UpsertSql(SqlServer(), clause); err == nil {
+		t.Error("sqlserver is not support upsert, it should return err")
+	}
+}
+
+func TestTableColInfo(t *testing.T) {
+	info := &TableColInfo{Field: "id", Key: PriFlag, Extra: "auto_increment"}
+	if !info.IsPri() || !info.IsAutoIncrement() {
+		t.Error("col info error")
+	}
+
+	info = &TableColInfo{Field: "name", Extra: "DEFAULT_GENERATED"}
+	if info.IsPri() || info.IsAutoIncrement() {
+		t.Error("col info error")
+	}
 }
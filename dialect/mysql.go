@@ -37,6 +37,11 @@ func (m *MysqlTable) GetAdapterName() string {
 	return "mysql"
 }
 
+// GetUpsertSql implements [Upserter].
+func (m *MysqlTable) GetUpsertSql(clause *UpsertClause) (string, error) {
+	return onDuplicateKeyUpdateSql(m, clause)
+}
+
 func (m *MysqlTable) GetColInfoMap(ctx context.Context, db DBer, tableName string) (map[string]*TableColInfo, error) {
 	sqlStr := fmt.Sprintf("SHOW COLUMNS FROM %s", tableName)
 	rows, err := db.QueryContext(ctx, sqlStr)
@@ -3,6 +3,7 @@ package dialect
 import (
 	"reflect"
 	"strings"
+	"time"
 
 	"gitee.com/xuesongtao/spellsql/v2/internal"
 	"gitee.com/xuesongtao/spellsql/v2/utils"
@@ -18,6 +19,7 @@ type ParsePlaceholder struct {
 	buf       *strings.Builder
 	waitParse string
 	args      []any
+	err       error // 解析时的错误, 如: dbType 没有注册
 }
 
 // NewParsePlaceholder 创建一个占位符解析器
@@ -25,7 +27,7 @@ type ParsePlaceholder struct {
 // sqlStr: 待解析的 sql 语句
 // args: 占位符对应的参数
 // 支持的占位符有:
-// ?: 常规占位符, 会根据数据库类型替换为对应数据库的占位符, 例如 mysql 为 ?, pg 为 $1, $2, ...
+// ?: 常规占位符, 会根据数据库类型替换为对应数据库的占位符, 例如 mysql 为 ?, pg 为 $1, $2, ..., sqlserver 为 @p1, @p2, ...
 // ?d: (特殊占位符)数字占位符, 会替换成数字参数, arg 支持 string/[]string
 // ?v: (特殊占位符)原样输出占位符, 会替换为原样参数, arg 支持 string
 func NewParsePlaceholder(dt DbType, sqlStr string, args ...any) *ParsePlaceholder {
@@ -199,8 +201,15 @@ func (p *ParsePlaceholder) replaceInternalArgs() *ParsePlaceholder {
 }
 
 // Parse 将占位符进行解析, 将占位符替换为对应的值
+// 注: dbType 没有注册时不会解析, 错误通过 Err 获取
 func (p *ParsePlaceholder) Parse() *ParsePlaceholder {
-	gd := GetDialect(p.dbType)
+	gd, err := GetDialect(p.dbType)
+	if err != nil {
+		p.err = err
+		p.buf.Reset()
+		p.buf.WriteString(p.waitParse)
+		return p
+	}
 	p.loopWaitParse(p.buf,
 		func(curIndex, argIndex, sqlSqlLastIndex int) int {
 			switch val := p.args[argIndex].(type) {
@@ -216,6 +225,8 @@ func (p *ParsePlaceholder) Parse() *ParsePlaceholder {
 				p.buf.WriteString(utils.UInt2Str(uint64(val)))
 			case uint32:
 				p.buf.WriteString(utils.UInt2Str(uint64(val)))
+			case time.Time:
+				p.buf.WriteString(WarpValue(gd, val.Format(internal.TimeFmt)))
 			default:
 				// slow path
 				reflectValue := reflect.ValueOf(val)
@@ -238,7 +249,7 @@ func (p *ParsePlaceholder) Parse() *ParsePlaceholder {
 	return p
 }
 
-// Replace 将占位符 "?" 替换为对应的数据库占位符, 例如 mysql 为 ?, pg 为 $1, $2, ...
+// Replace 将占位符 "?" 替换为对应的数据库占位符, 例如 mysql 为 ?, pg 为 $1, $2, ..., sqlserver 为 @p1, @p2, ...
 func (p *ParsePlaceholder) Replace() *ParsePlaceholder {
 	p.loopWaitParse(p.buf,
 		func(curIndex, argIndex, lastIndex int) int {
@@ -246,6 +257,9 @@ func (p *ParsePlaceholder) Replace() *ParsePlaceholder {
 			case Postgres:
 				p.buf.WriteString("$")
 				p.buf.WriteString(utils.Int2Str(int64(argIndex + 1)))
+			case SQLServer:
+				p.buf.WriteString("@p")
+				p.buf.WriteString(utils.Int2Str(int64(argIndex + 1)))
 			default:
 				p.buf.WriteString("?")
 			}
@@ -255,6 +269,11 @@ func (p *ParsePlaceholder) Replace() *ParsePlaceholder {
 	return p
 }
 
+// Err 获取解析时的错误
+func (p *ParsePlaceholder) Err() error {
+	return p.err
+}
+
 // Result 获取最终的 sql 语句
 func (p *ParsePlaceholder) Result() string {
 	return p.buf.String()
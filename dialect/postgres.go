@@ -47,6 +47,11 @@ func (p *PgTable) GetAdapterName() string {
 	return "pg"
 }
 
+// GetUpsertSql implements [Upserter].
+func (p *PgTable) GetUpsertSql(clause *UpsertClause) (string, error) {
+	return onConflictSql(p, clause)
+}
+
 // GetLimitSql implements [Dialect].
 func (p *PgTable) GetLimitSql(limit int, offset int) string {
 	return "LIMIT " + utils.Int2Str(int64(limit)) + " OFFSET " + utils.Int2Str(int64(offset))
@@ -0,0 +1,93 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gitee.com/xuesongtao/spellsql/v2/internal"
+	"gitee.com/xuesongtao/spellsql/v2/utils"
+)
+
+type SqliteTable struct {
+}
+
+// Sqlite
+func Sqlite() *SqliteTable {
+	return &SqliteTable{}
+}
+
+// GetWarpColSymbol implements [Dialect].
+func (s *SqliteTable) GetWarpColSymbol() string {
+	return `"`
+}
+
+// GetWarpValueStrSymbol implements [Dialect].
+func (s *SqliteTable) GetWarpValueStrSymbol() string {
+	return `'`
+}
+
+// GetValueEscapeMap implements [Dialect].
+// 注: sqlite 字符串不支持反斜杠转义, 只需要将单引号转义为两个单引号
+func (s *SqliteTable) GetValueEscapeMap() map[byte][]byte {
+	return map[byte][]byte{
+		'\'': {'\'', '\''},
+	}
+}
+
+// GetLimitSql implements [Dialect].
+func (s *SqliteTable) GetLimitSql(limit int, offset int) string {
+	return "LIMIT " + utils.Int2Str(int64(limit)) + " OFFSET " + utils.Int2Str(int64(offset))
+}
+
+func (s *SqliteTable) GetAdapterName() string {
+	return "sqlite"
+}
+
+// GetUpsertSql implements [Upserter].
+func (s *SqliteTable) GetUpsertSql(clause *UpsertClause) (string, error) {
+	return onConflictSql(s, clause)
+}
+
+// GetColInfoMap 通过 PRAGMA table_info 获取表元信息
+// 返回列: cid, name, type, notnull, dflt_value, pk
+func (s *SqliteTable) GetColInfoMap(ctx context.Context, db DBer, tableName string) (map[string]*TableColInfo, error) {
+	sqlStr := fmt.Sprintf("PRAGMA table_info(%s)", tableName)
+	rows, err := db.QueryContext(ctx, sqlStr)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite query is failed, err: %v, sqlStr: %v", err, sqlStr)
+	}
+	defer rows.Close()
+
+	cacheCol2InfoMap := make(map[string]*TableColInfo)
+	for rows.Next() {
+		var (
+			info    TableColInfo
+			notNull int
+			pk      int
+			colType sql.NullString
+		)
+		err = rows.Scan(&info.Index, &info.Field, &colType, &notNull, &info.Default, &pk)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite scan is failed, err: %v", err)
+		}
+		info.Type = colType.String
+		info.Null = "YES"
+		if notNull == 1 {
+			info.Null = NotNullFlag
+		}
+		if pk > 0 {
+			info.Key = PriFlag
+		}
+		cacheCol2InfoMap[info.Field] = &info
+	}
+	return cacheCol2InfoMap, nil
+}
+
+// GetDefaultVal sqlite 在 VALUES 中不支持 DEFAULT 关键字, 所以需要使用表定义的默认值, 没有的话就为 NULL
+func (s *SqliteTable) GetDefaultVal(col string, colInfo *TableColInfo) internal.RawSql {
+	if colInfo != nil && colInfo.Default.Valid {
+		return internal.RawSql(colInfo.Default.String)
+	}
+	return internal.NULL
+}
@@ -0,0 +1,142 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gitee.com/xuesongtao/spellsql/v2/internal"
+	"gitee.com/xuesongtao/spellsql/v2/utils"
+)
+
+type SqlServerTable struct {
+	initArgs []string
+}
+
+// SqlServer, 默认模式: dbo
+// initArgs 允许自定义两个参数
+// initArgs[0] 为 schema
+// initArgs[1] 为 table name (此参数可以忽略, 因为 orm 内部会处理该值)
+func SqlServer(initArgs ...string) *SqlServerTable {
+	obj := &SqlServerTable{initArgs: make([]string, 2)}
+	l := len(initArgs)
+	switch l {
+	case 1:
+		obj.initArgs[0] = initArgs[0]
+	case 2:
+		obj.initArgs[0] = initArgs[0]
+		obj.initArgs[1] = initArgs[1]
+	}
+	if l == 0 {
+		obj.initArgs[0] = "dbo"
+	}
+	return obj
+}
+
+// GetWarpColSymbol implements [Dialect].
+func (s *SqlServerTable) GetWarpColSymbol() string {
+	return "["
+}
+
+// GetWarpColSymbols implements [ColWarper].
+func (s *SqlServerTable) GetWarpColSymbols() (string, string) {
+	return "[", "]"
+}
+
+// GetWarpValueStrSymbol implements [Dialect].
+func (s *SqlServerTable) GetWarpValueStrSymbol() string {
+	return `'`
+}
+
+// GetValueEscapeMap implements [Dialect].
+// 注: sqlserver 字符串不支持反斜杠转义, 只需要将单引号转义为两个单引号
+func (s *SqlServerTable) GetValueEscapeMap() map[byte][]byte {
+	return map[byte][]byte{
+		'\'': {'\'', '\''},
+	}
+}
+
+// GetLimitSql implements [Dialect].
+// 注: OFFSET/FETCH 必须跟在 ORDER BY 后面
+func (s *SqlServerTable) GetLimitSql(limit int, offset int) string {
+	return "OFFSET " + utils.Int2Str(int64(offset)) + " ROWS FETCH NEXT " + utils.Int2Str(int64(limit)) + " ROWS ONLY"
+}
+
+// GetLimitDefaultOrderBy implements [LimitOrderByer].
+func (s *SqlServerTable) GetLimitDefaultOrderBy() string {
+	return "ORDER BY (SELECT NULL)"
+}
+
+// GetSavepointSql implements [Savepointer].
+func (s *SqlServerTable) GetSavepointSql(name string) string {
+	return "SAVE TRANSACTION " + name
+}
+
+// GetRollbackToSavepointSql implements [Savepointer].
+func (s *SqlServerTable) GetRollbackToSavepointSql(name string) string {
+	return "ROLLBACK TRANSACTION " + name
+}
+
+// GetReleaseSavepointSql implements [Savepointer].
+// 注: sqlserver 没有释放保存点的语法
+func (s *SqlServerTable) GetReleaseSavepointSql(name string) string {
+	return ""
+}
+
+func (s *SqlServerTable) GetAdapterName() string {
+	return "sqlserver"
+}
+
+// GetColInfoMap 通过 INFORMATION_SCHEMA.COLUMNS 获取表元信息
+func (s *SqlServerTable) GetColInfoMap(ctx context.Context, db DBer, tableName string) (map[string]*TableColInfo, error) {
+	sqlStr := `
+		SELECT
+			c.COLUMN_NAME,
+			c.DATA_TYPE,
+			c.IS_NULLABLE,
+			c.COLUMN_DEFAULT,
+			CASE WHEN pk.COLUMN_NAME IS NULL THEN '' ELSE 'PRI' END AS COLUMN_KEY,
+			CASE WHEN COLUMNPROPERTY(OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME)), c.COLUMN_NAME, 'IsIdentity') = 1
+				THEN 'auto_increment' ELSE '' END AS EXTRA
+		FROM
+			INFORMATION_SCHEMA.COLUMNS c
+		LEFT JOIN (
+			SELECT ku.TABLE_SCHEMA, ku.TABLE_NAME, ku.COLUMN_NAME
+			FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+			JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE ku
+				ON tc.CONSTRAINT_SCHEMA = ku.CONSTRAINT_SCHEMA AND tc.CONSTRAINT_NAME = ku.CONSTRAINT_NAME
+			WHERE tc.CONSTRAINT_TYPE = 'PRIMARY KEY'
+		) pk
+			ON c.TABLE_SCHEMA = pk.TABLE_SCHEMA AND c.TABLE_NAME = pk.TABLE_NAME AND c.COLUMN_NAME = pk.COLUMN_NAME
+		WHERE
+			c.TABLE_SCHEMA = @p1 AND c.TABLE_NAME = @p2
+		ORDER BY c.ORDINAL_POSITION
+		`
+	rows, err := db.QueryContext(ctx, sqlStr, s.initArgs[0], tableName)
+	if err != nil {
+		return nil, fmt.Errorf("sqlserver query is failed, err: %v, sqlStr: %v", err, sqlStr)
+	}
+	defer rows.Close()
+
+	cacheCol2InfoMap := make(map[string]*TableColInfo)
+	var index int
+	for rows.Next() {
+		var (
+			info TableColInfo
+			key  sql.NullString
+		)
+		err = rows.Scan(&info.Field, &info.Type, &info.Null, &info.Default, &key, &info.Extra)
+		if err != nil {
+			return nil, fmt.Errorf("sqlserver scan is failed, err: %v", err)
+		}
+		info.Key = key.String
+		info.Index = index
+		cacheCol2InfoMap[info.Field] = &info
+		index++
+	}
+	return cacheCol2InfoMap, nil
+}
+
+func (s *SqlServerTable) GetDefaultVal(col string, colInfo *TableColInfo) internal.RawSql {
+	return internal.DEFAULT
+}
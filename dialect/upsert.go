@@ -0,0 +1,100 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UpsertClause 新增冲突时的处理, 列名不需要包裹
+type UpsertClause struct {
+	ConflictCols []string          // 冲突的列, 如: 主键/唯一索引的列
+	UpdateCols   []string          // 冲突时使用新值更新的列
+	UpdateExprs  map[string]string // 冲突时使用表达式更新的列, key: 列名, value: 表达式(原样输出), 如: {"num": "num + 1"}
+	DoNothing    bool              // 冲突时不处理
+}
+
+// Upserter 支持新增冲突时更新(upsert)的方言需要实现, 用于 Insert 的冲突处理
+type Upserter interface {
+	GetUpsertSql(clause *UpsertClause) (string, error) // 返回追加在 VALUES 后的语句
+}
+
+// UpsertSql 获取新增冲突时的处理语句, 方言没有实现 Upserter 时返回错误
+func UpsertSql(d Dialect, clause *UpsertClause) (string, error) {
+	u, ok := d.(Upserter)
+	if !ok {
+		return "", fmt.Errorf("dialect %T is not support upsert, it should implement Upserter", d)
+	}
+	return u.GetUpsertSql(clause)
+}
+
+// getUpdateSets 获取冲突时更新的内容, newVal 返回新值的写法, 如: VALUES(`col`)
+func (c *UpsertClause) getUpdateSets(d Dialect, newVal func(warpCol string) string) []string {
+	sets := make([]string, 0, len(c.UpdateCols)+len(c.UpdateExprs))
+	for _, col := range c.UpdateCols {
+		if _, ok := c.UpdateExprs[col]; ok { // 以表达式为准
+			continue
+		}
+		wCol := WarpCol(d, col)
+		sets = append(sets, wCol+"="+newVal(wCol))
+	}
+
+	// 按列名排序, 保证生成的 sql 一致
+	exprCols := make([]string, 0, len(c.UpdateExprs))
+	for col := range c.UpdateExprs {
+		exprCols = append(exprCols, col)
+	}
+	sort.Strings(exprCols)
+	for _, col := range exprCols {
+		sets = append(sets, WarpCol(d, col)+"="+c.UpdateExprs[col])
+	}
+	return sets
+}
+
+// warpJoinCols 包裹后用逗号拼接
+func warpJoinCols(d Dialect, cols []string) string {
+	warpCols := make([]string, len(cols))
+	for i, col := range cols {
+		warpCols[i] = WarpCol(d, col)
+	}
+	return strings.Join(warpCols, ", ")
+}
+
+// onDuplicateKeyUpdateSql mysql 语法, 如: ON DUPLICATE KEY UPDATE `name`=VALUES(`name`)
+// DoNothing 时通过将冲突列更新为自身实现, 如: ON DUPLICATE KEY UPDATE `id`=`id`
+func onDuplicateKeyUpdateSql(d Dialect, clause *UpsertClause) (string, error) {
+	if clause.DoNothing {
+		if len(clause.ConflictCols) == 0 {
+			return "", errors.New("upsert do nothing conflict cols is empty")
+		}
+		wCol := WarpCol(d, clause.ConflictCols[0])
+		return "ON DUPLICATE KEY UPDATE " + wCol + "=" + wCol, nil
+	}
+
+	sets := clause.getUpdateSets(d, func(warpCol string) string { return "VALUES(" + warpCol + ")" })
+	if len(sets) == 0 {
+		return "", errors.New("upsert update cols is empty")
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "), nil
+}
+
+// onConflictSql postgres/sqlite 语法, 如: ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name"
+func onConflictSql(d Dialect, clause *UpsertClause) (string, error) {
+	target := ""
+	if len(clause.ConflictCols) > 0 {
+		target = " (" + warpJoinCols(d, clause.ConflictCols) + ")"
+	}
+	if clause.DoNothing {
+		return "ON CONFLICT" + target + " DO NOTHING", nil
+	}
+
+	if target == "" {
+		return "", errors.New("upsert conflict cols is empty")
+	}
+	sets := clause.getUpdateSets(d, func(warpCol string) string { return "EXCLUDED." + warpCol })
+	if len(sets) == 0 {
+		return "", errors.New("upsert update cols is empty")
+	}
+	return "ON CONFLICT" + target + " DO UPDATE SET " + strings.Join(sets, ", "), nil
+}
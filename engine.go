@@ -0,0 +1,157 @@
+package spellsql
+
+import (
+	"context"
+	"time"
+
+	"gitee.com/xuesongtao/spellsql/v2/dialect"
+	"gitee.com/xuesongtao/spellsql/v2/internal"
+	"gitee.com/xuesongtao/spellsql/v2/utils"
+)
+
+// Engine 数据库引擎, 用于隔离不同数据库的配置和缓存
+// 如: 同一个进程中同时使用 mysql 和 pg, 可以分别创建 Engine, 它们的 dbType/logger/afterHook/tag/表元信息缓存 互不影响
+// 注: 通过 NewTable/NewSql 等包函数创建的对象仍使用全局配置
+type Engine struct {
+	db        DBer
+	dbType    dialect.DbType
+	tag       string                                   // 解析 struct 中字段名的 tag
+	logger    Logger                                   // 日志
+	afterHook func(ctx context.Context, ah *AfterHook) // 执行 Query/Exec 后回调
+
+	autoIncLockMode  uint8  // mysql innodb_autoinc_lock_mode, 用于回填自增主键
+	autoIncIncrement int64  // mysql auto_increment_increment, 用于批量回填自增主键
+	softDelete       bool   // 是否开启软删除
+	softDeleteCol    string // 软删除的列名, 为空时自动检测
+	autoTimestamp    bool   // 是否开启自动时间列
+	createdAtCol     string // 创建时间列
+	updatedAtCol     string // 更新时间列
+	nowFn            func() time.Time
+
+	cacheTableName2ColInfoMap      *utils.LRUCache // 缓存表的字段元信息, key: tableName, value: tableColInfo
+	cacheStructType2StructFieldMap *utils.LRUCache // 缓存结构体 reflect.Type 对应的 field 信息, key: struct 的 reflect.Type, value: map[colName]structField; 展开后的字段, key: structFieldPathKey, value: []structFieldPath
+}
+
+// NewEngine 初始化
+// dt 如果没有注册, 通过该 Engine 创建的 Table 会在执行时返回错误
+func NewEngine(db DBer, dt dialect.DbType) *Engine {
+	e := &Engine{
+		db:                             db,
+		dbType:                         dt,
+		tag:                            internal.DefaultTableTag,
+		logger:                         internal.NewLogger(),
+		autoIncLockMode:                AutoIncLockModeInterleaved,
+		autoIncIncrement:               1,
+		cacheTableName2ColInfoMap:      utils.NewLRU(),
+		cacheStructType2StructFieldMap: utils.NewLRU(),
+	}
+	e.afterHook = func(ctx context.Context, ah *AfterHook) {
+		logAfterHook(e.logger, ctx, ah)
+	}
+	return e
+}
+
+// SetLogger 设置 logger
+func (e *Engine) SetLogger(logger Logger) *Engine {
+	if logger == nil {
+		return e
+	}
+	e.logger = logger
+	return e
+}
+
+// AfterHook 设置执行 Query/Exec 后回调
+func (e *Engine) AfterHook(f func(ctx context.Context, ah *AfterHook)) *Engine {
+	if f == nil {
+		return e
+	}
+	e.afterHook = f
+	return e
+}
+
+// AutoIncLockMode 设置 mysql innodb_autoinc_lock_mode, 同 Table.AutoIncLockMode
+func (e *Engine) AutoIncLockMode(mode uint8) *Engine {
+	e.autoIncLockMode = mode
+	return e
+}
+
+// AutoIncIncrement 设置 mysql auto_increment_increment, 同 Table.AutoIncIncrement
+func (e *Engine) AutoIncIncrement(increment int64) *Engine {
+	if increment > 0 {
+		e.autoIncIncrement = increment
+	}
+	return e
+}
+
+// SoftDelete 所有 Table 开启软删除, 同 Table.SoftDelete, 表中没有软删除列时不处理
+func (e *Engine) SoftDelete(col ...string) *Engine {
+	e.softDelete = true
+	if len(col) > 0 {
+		e.softDeleteCol = col[0]
+	}
+	return e
+}
+
+// AutoTimestamp 所有 Table 开启自动维护时间列, 同 Table.AutoTimestamp
+func (e *Engine) AutoTimestamp(cols ...string) *Engine {
+	e.autoTimestamp = true
+	e.createdAtCol, e.updatedAtCol = getAutoTimestampCols(cols...)
+	return e
+}
+
+// NowFn 设置获取当前时间的方法, 同 Table.NowFn
+func (e *Engine) NowFn(fn func() time.Time) *Engine {
+	if fn == nil {
+		return e
+	}
+	e.nowFn = fn
+	return e
+}
+
+// Tag 设置解析 struct 中字段名的 tag, 默认 defaultTableTag
+// 注: 需要在使用前设置
+func (e *Engine) Tag(tag string) *Engine {
+	if utils.Null(tag) {
+		return e
+	}
+	e.tag = tag
+	// tag 变化后, 之前解析的结构体字段就不能用了
+	e.cacheStructType2StructFieldMap = utils.NewLRU()
+	return e
+}
+
+// DB 获取 db
+func (e *Engine) DB() DBer {
+	return e.db
+}
+
+// DbType 获取数据库类型
+func (e *Engine) DbType() dialect.DbType {
+	return e.dbType
+}
+
+// Logger 获取 logger
+func (e *Engine) Logger() Logger {
+	return e.logger
+}
+
+// NewTable 初始化, args 同 NewTable
+func (e *Engine) NewTable(args ...string) *Table {
+	t := new(Table)
+	t.engine = e
+	t.Reset()
+	t.initDb(e.db, args...)
+	return t.DbType(e.dbType)
+}
+
+// NewTableWithCtx 创建一个带有 context 的 Table 对象
+func (e *Engine) NewTableWithCtx(ctx context.Context, args ...string) *Table {
+	return e.NewTable(args...).Ctx(ctx)
+}
+
+// NewSql 初始化, 用法同 NewSql
+func (e *Engine) NewSql(sqlStr string, args ...any) *SqlStrObj {
+	obj := &SqlStrObj{engine: e, dbType: e.dbType}
+	obj.initSql(sqlStr, args...)
+	return obj
+}
@@ -0,0 +1,106 @@
+package spellsql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitee.com/xuesongtao/spellsql/v2/dialect"
+)
+
+type testLogger struct {
+	logs []string
+}
+
+func (l *testLogger) Info(ctx context.Context, v ...any) {
+	l.logs = append(l.logs, fmt.Sprint(v...))
+}
+
+func (l *testLogger) Error(ctx context.Context, v ...any) {
+	l.logs = append(l.logs, fmt.Sprint(v...))
+}
+
+func (l *testLogger) Warning(ctx context.Context, v ...any) {
+	l.logs = append(l.logs, fmt.Sprint(v...))
+}
+
+func TestEngine(t *testing.T) {
+	mysqlEngine := NewEngine(nil, dialect.MySQL)
+	pgEngine := NewEngine(nil, dialect.Postgres)
+
+	t.Run("dbType", func(t *testing.T) {
+		sqlStr, _ := mysqlEngine.NewTable("man").Select("name").Where("id = ?", 1).GetBuilder().GetSql2Args()
+		if sqlStr != "SELECT `name` FROM man WHERE id = ?" {
+			t.Error("mysql engine error, result:", sqlStr)
+		}
+
+		sqlStr, _ = pgEngine.NewTable("man").Select("name").Where("id = ?", 1).GetBuilder().GetSql2Args()
+		if sqlStr != `SELECT "name" FROM man WHERE id = $1` {
+			t.Error("pg engine error, result:", sqlStr)
+		}
+
+		sqlStr = pgEngine.NewSql("SELECT name FROM man WHERE name = ?", "it's").FmtSql()
+		if sqlStr != "SELECT name FROM man WHERE name = 'it''s'" {
+			t.Error("pg engine sql error, result:", sqlStr)
+		}
+	})
+
+	t.Run("logger", func(t *testing.T) {
+		logger := &testLogger{}
+		NewEngine(nil, dialect.MySQL).SetLogger(logger).NewSql("SELECT name FROM man").GetSqlStr()
+		if len(logger.logs) != 1 || !strings.Contains(logger.logs[0], "SELECT name FROM man;") {
+			t.Error("engine logger error, result:", logger.logs)
+		}
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		mysqlEngine.cacheTableName2ColInfoMap.Store("man", map[string]*dialect.TableColInfo{})
+		if _, ok := pgEngine.cacheTableName2ColInfoMap.Load("man"); ok {
+			t.Error("engine cache should be isolated")
+		}
+		if _, ok := cacheTableName2ColInfoMap.Load("man"); ok {
+			t.Error("engine cache should not store in global")
+		}
+	})
+
+	t.Run("struct field paths cache", func(t *testing.T) {
+		type embedded struct {
+			Name string `json:"name"`
+		}
+		type engineCacheMan struct {
+			embedded
+			Id int32 `json:"id"`
+		}
+		ty := reflect.TypeOf(engineCacheMan{})
+		mysqlEngine.NewTable("man").getStructFieldPaths(ty)
+		key := structFieldPathKey{ty: ty, tag: mysqlEngine.tag}
+		if _, ok := mysqlEngine.cacheStructType2StructFieldMap.Load(key); !ok {
+			t.Error("struct field paths should store in engine")
+		}
+		if _, ok := pgEngine.cacheStructType2StructFieldMap.Load(key); ok {
+			t.Error("engine cache should be isolated")
+		}
+		if _, ok := cacheStructType2StructFieldMap.Load(key); ok {
+			t.Error("engine cache should not store in global")
+		}
+	})
+
+	t.Run("unknown dbType", func(t *testing.T) {
+		_, err := NewEngine(nil, dialect.DbType(1000)).NewTable("man").Exec()
+		if err == nil {
+			t.Error("it should be unknown db type")
+		}
+	})
+}
+
+func TestGlobalDbTypeOfUnknown(t *testing.T) {
+	old := dialect.DefaultDbType
+	if err := GlobalDbType(dialect.DbType(200)); err == nil {
+		t.Error("unknown db type should be failed")
+	}
+	if dialect.DefaultDbType != old {
+		t.Errorf("default db type should not change, got: %d, want: %d", dialect.DefaultDbType, old)
+	}
+}
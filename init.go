@@ -30,7 +30,7 @@ const (
 
 var (
 	cacheTableName2ColInfoMap      = utils.NewLRU() // 缓存表的字段元信息, key: tableName, value: tableColInfo
-	cacheStructType2StructFieldMap = utils.NewLRU() // 缓存结构体 reflect.Type 对应的 field 信息, key: struct 的 reflect.Type, value: map[colName]structField
+	cacheStructType2StructFieldMap = utils.NewLRU() // 缓存结构体 reflect.Type 对应的 field 信息, key: struct 的 reflect.Type, value: map[colName]structField; 展开后的字段, key: structFieldPathKey, value: []structFieldPath
 
 	// 常用就缓存下
 	cacheNullString = sync.Pool{New: func() any { return new(sql.NullString) }}
@@ -46,10 +46,15 @@ var (
 	globalAfterHook = defaultAfterHook
 )
 
-func GlobalDbType(dt dialect.DbType) {
+// GlobalDbType 设置全局数据库类型, 只会生效一次, 如果 dt 没有注册会返回错误且不会生效
+func GlobalDbType(dt dialect.DbType) error {
+	if _, err := dialect.GetDialect(dt); err != nil {
+		return err
+	}
 	globalDbTypeOnce.Do(func() {
 		dialect.DefaultDbType = dt
 	})
+	return nil
 }
 
 func GlobalAfterHook(f func(ctx context.Context, ah *AfterHook)) {
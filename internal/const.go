@@ -32,7 +32,26 @@ const (
 
 const (
 	DefaultTableTag        = "json"
-	DefaultBatchSelectSize = 10 // 批量查询默认条数
+	DefaultBatchSelectSize = 10                    // 批量查询默认条数
+	DefaultBatchInsertSize = 1000                  // 分批新增默认每批条数
+	MaxPlaceholderNum      = 65535                 // 单条 sql 最多的占位符数, mysql/postgres 都为 65535
+	TimeFmt                = "2006-01-02 15:04:05" // 解析 time.Time 的格式
+
+	// 软删除默认列名, 按顺序检测
+	SoftDeleteAtCol   = "deleted_at" // 删除时间, 为 NULL 时表示未删除
+	SoftDeleteFlagCol = "is_deleted" // 删除标记, 为 0 时表示未删除
+
+	// 自动时间默认列名
+	CreatedAtCol = "created_at" // 创建时间
+	UpdatedAtCol = "updated_at" // 更新时间
+
+	VersionCol = "version" // 乐观锁默认列名
+
+	// 关联关系 tag, 如: rel:"has_many,foreign=user_id"
+	RelTag       = "rel"
+	RelHasOne    = "has_one"
+	RelHasMany   = "has_many"
+	RelBelongsTo = "belongs_to"
 )
 
 // 原样输入
@@ -16,4 +16,6 @@ var (
 	FindOneDestTypeErr    = errors.New("dest should is struct/oneField/map")
 	FindAllDestTypeErr    = errors.New("dest should is struct/oneField/map slice")
 	BuilderIsNilErr       = errors.New("builder is nil, you should check is first call Select/Insert/Update/Delete")
+	TxNotSupportErr       = errors.New("db is not support tx, it should be TxBeginner/*Tx/*sql.Tx")
+	StaleObjectErr        = errors.New("object is stale, it has been modified")
 )
@@ -0,0 +1,124 @@
+package spellsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+)
+
+// mockResult 模拟数据库返回的结果
+type mockResult struct {
+	cols         []string
+	rows         [][]driver.Value
+	lastInsertId int64
+	rowsAffected int64
+	err          error
+}
+
+// mockHandler 根据 sql 和参数返回结果, 会被并发调用
+type mockHandler func(query string, args []driver.Value) *mockResult
+
+// mockDb 模拟的数据库, 用于不依赖数据库的测试, 会记录执行的 sql
+type mockDb struct {
+	mu      sync.Mutex
+	handler mockHandler
+	queries []string
+}
+
+// newMockDb 创建模拟的数据库
+func newMockDb(handler mockHandler) (*sql.DB, *mockDb) {
+	m := &mockDb{handler: handler}
+	return sql.OpenDB(m), m
+}
+
+// Queries 返回执行过的 sql
+func (m *mockDb) Queries() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.queries...)
+}
+
+func (m *mockDb) do(query string, args []driver.Value) *mockResult {
+	m.mu.Lock()
+	m.queries = append(m.queries, query)
+	m.mu.Unlock()
+
+	var res *mockResult
+	if m.handler != nil {
+		res = m.handler(query, args)
+	}
+	if res == nil {
+		res = &mockResult{}
+	}
+	return res
+}
+
+func (m *mockDb) Connect(context.Context) (driver.Conn, error) { return &mockConn{m}, nil }
+func (m *mockDb) Driver() driver.Driver                        { return nil }
+
+type mockConn struct{ m *mockDb }
+
+func (c *mockConn) Prepare(query string) (driver.Stmt, error) { return &mockStmt{c.m, query}, nil }
+func (c *mockConn) Close() error                              { return nil }
+func (c *mockConn) Begin() (driver.Tx, error)                 { c.m.do("BEGIN", nil); return c, nil }
+func (c *mockConn) Commit() error                             { c.m.do("COMMIT", nil); return nil }
+func (c *mockConn) Rollback() error                           { c.m.do("ROLLBACK", nil); return nil }
+
+type mockStmt struct {
+	m     *mockDb
+	query string
+}
+
+func (s *mockStmt) Close() error  { return nil }
+func (s *mockStmt) NumInput() int { return -1 }
+
+func (s *mockStmt) Exec(args []driver.Value) (driver.Result, error) {
+	res := s.m.do(s.query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return res, nil
+}
+
+func (s *mockStmt) Query(args []driver.Value) (driver.Rows, error) {
+	res := s.m.do(s.query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &mockRows{res: res}, nil
+}
+
+func (r *mockResult) LastInsertId() (int64, error) { return r.lastInsertId, nil }
+func (r *mockResult) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+type mockRows struct {
+	res *mockResult
+	i   int
+}
+
+func (r *mockRows) Columns() []string { return r.res.cols }
+func (r *mockRows) Close() error      { return nil }
+
+func (r *mockRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.res.rows) {
+		return io.EOF
+	}
+	copy(dest, r.res.rows[r.i])
+	r.i++
+	return nil
+}
+
+// mockManCols 模拟 mysql man 表的 SHOW COLUMNS 结果
+func mockManCols() *mockResult {
+	return &mockResult{
+		cols: []string{"Field", "Type", "Null", "Key", "Default", "Extra"},
+		rows: [][]driver.Value{
+			{"id", "int", "NO", "PRI", nil, "auto_increment"},
+			{"name", "varchar(10)", "NO", "", nil, ""},
+			{"age", "int", "NO", "", nil, ""},
+			{"addr", "varchar(50)", "YES", "", nil, ""},
+		},
+	}
+}
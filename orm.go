@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"gitee.com/xuesongtao/spellsql/v2/builder"
 	"gitee.com/xuesongtao/spellsql/v2/dialect"
@@ -26,17 +27,32 @@ type handleStructField struct {
 
 // structField 结构体字段信息
 type structField struct {
-	offsetIndex int
-	tagName     string
+	index   []int // 字段的索引路径, 匿名嵌套的结构体字段长度大于 1
+	tagName string
+	nested  bool // 是否为通过列名前缀映射到嵌套结构体的字段, 如: u.name, u__name
+}
+
+// structFieldPath 结构体字段及其索引路径
+type structFieldPath struct {
+	index []int
+	field reflect.StructField
+}
+
+// structFieldPathKey 结构体展开后的字段在 getStructFieldCache 中的 key, 不同 tag 解析的同名覆盖结果不同
+type structFieldPathKey struct {
+	ty  reflect.Type
+	tag string
 }
 
 // Table 表的信息
 type Table struct {
+	engine                   *Engine // 为 nil 时使用全局配置
 	ctx                      context.Context
 	db                       DBer
 	dbType                   dialect.DbType
 	err                      error                            // 错误信息
 	printSqlCallSkip         uint8                            // 标记打印 sql 时, 需要跳过的 skip, 该参数为 runtime.Caller(skip)
+	callInfo                 []string                         // 分批执行时在入口处记录的调用位置, 不为空时打印 sql 使用该位置
 	destTypeFlag             uint8                            // 查询时, 用于标记 dest 类型的
 	isPrintSql               bool                             // 标记是否打印 sql
 	tag                      string                           // 记录解析 struct 中字段名的 tag
@@ -46,6 +62,23 @@ type Table struct {
 	cacheCol2InfoMap         map[string]*dialect.TableColInfo // 记录该表的所有字段名
 	waitHandleStructFieldMap map[string]*handleStructField    // 处理 struct 字段的方法, key: tag, value: 处理方法集
 	afterHook                func(ah *AfterHook)              // 执行 Query/Exec 后回调
+	insertOp                 internal.OpType                  // Insert 的类型
+	insertObjs               []any                            // Insert 的对象, 用于回填主键等
+	autoIncLockMode          uint8                            // mysql innodb_autoinc_lock_mode, 用于回填自增主键
+	autoIncIncrement         int64                            // mysql auto_increment_increment, 用于批量回填自增主键
+	returningCols            []string                         // Insert 后需要返回的列
+	softDelete               bool                             // 是否开启软删除
+	softDeleteCol            string                           // 软删除的列名, 为空时自动检测
+	softDeleteApplied        bool                             // 标记查询是否已添加软删除条件
+	unscoped                 bool                             // 忽略软删除
+	autoTimestamp            bool                             // 是否开启自动时间列
+	createdAtCol             string                           // 创建时间列
+	updatedAtCol             string                           // 更新时间列
+	nowFn                    func() time.Time                 // 获取当前时间, 为 nil 时使用 time.Now
+	versionCol               string                           // 乐观锁的版本号列, 为空时不开启
+	versionObj               any                              // 乐观锁 Update 的对象, 用于回填版本号
+	preloads                 []string                         // 查询后需要预加载的关联字段
+	batchInTx                bool                             // 分批执行时是否在同一个事务中
 }
 
 // NewTable 初始化
@@ -88,6 +121,7 @@ func (t *Table) Reset() {
 	t.db = nil
 	t.dbType = dialect.DefaultDbType
 	t.printSqlCallSkip = 2
+	t.callInfo = nil
 	t.destTypeFlag = 0
 	t.isPrintSql = true
 	t.tag = internal.DefaultTableTag
@@ -96,7 +130,62 @@ func (t *Table) Reset() {
 	t.builder = nil
 	t.cacheCol2InfoMap = nil
 	t.waitHandleStructFieldMap = nil
-	t.AfterHook(globalAfterHook)
+	t.insertOp = internal.None
+	t.insertObjs = nil
+	t.returningCols = nil
+	t.autoIncLockMode = AutoIncLockModeInterleaved
+	t.autoIncIncrement = 1
+	t.softDelete = false
+	t.softDeleteCol = ""
+	t.softDeleteApplied = false
+	t.unscoped = false
+	t.autoTimestamp = false
+	t.createdAtCol = ""
+	t.updatedAtCol = ""
+	t.nowFn = nil
+	t.versionCol = ""
+	t.versionObj = nil
+	t.preloads = nil
+	t.batchInTx = false
+	afterHook := globalAfterHook
+	if e := t.engine; e != nil {
+		t.dbType = e.dbType
+		t.tag = e.tag
+		t.autoIncLockMode = e.autoIncLockMode
+		t.autoIncIncrement = e.autoIncIncrement
+		t.softDelete = e.softDelete
+		t.softDeleteCol = e.softDeleteCol
+		t.autoTimestamp = e.autoTimestamp
+		t.createdAtCol = e.createdAtCol
+		t.updatedAtCol = e.updatedAtCol
+		t.nowFn = e.nowFn
+		afterHook = e.afterHook
+	}
+	t.AfterHook(afterHook)
+}
+
+// getLogger 获取 logger
+func (t *Table) getLogger() Logger {
+	if t.engine != nil {
+		return t.engine.logger
+	}
+	return sLog
+}
+
+// getColInfoCache 获取表元信息缓存
+func (t *Table) getColInfoCache() *utils.LRUCache {
+	if t.engine != nil {
+		return t.engine.cacheTableName2ColInfoMap
+	}
+	return cacheTableName2ColInfoMap
+}
+
+// getStructFieldCache 获取结构体字段信息缓存
+func (t *Table) getStructFieldCache() *utils.LRUCache {
+	if t.engine != nil {
+		return t.engine.cacheStructType2StructFieldMap
+	}
+	return cacheStructType2StructFieldMap
 }
 
 // Ctx 设置 context
@@ -118,11 +207,33 @@ func (t *Table) AfterHook(f func(context.Context, *AfterHook)) *Table {
 	return t
 }
 
+// DbType 设置数据库类型, 如果 dt 没有注册, 会在执行时返回错误
 func (t *Table) DbType(dt dialect.DbType) *Table {
+	if _, err := dialect.GetDialect(dt); err != nil {
+		t.err = err
+		return t
+	}
 	t.dbType = dt
 	return t
 }
 
+// AutoIncLockMode 设置 mysql innodb_autoinc_lock_mode, 默认 AutoIncLockModeInterleaved
+// 设置为 AutoIncLockModeTraditional/AutoIncLockModeConsecutive 时, 批量 Insert 指针对象后会回填自增主键
+// 注: 需要和数据库的配置一致, 否则回填的主键可能不正确
+func (t *Table) AutoIncLockMode(mode uint8) *Table {
+	t.autoIncLockMode = mode
+	return t
+}
+
+// AutoIncIncrement 设置 mysql auto_increment_increment(自增步长), 默认 1, 批量回填自增主键时第 i 个对象的主键为 LastInsertId + i * increment
+// 注: 需要和数据库的配置一致, 如: Galera/多主时步长一般大于 1
+func (t *Table) AutoIncIncrement(increment int64) *Table {
+	if increment > 0 {
+		t.autoIncIncrement = increment
+	}
+	return t
+}
+
 // IsPrintSql 是否打印 sql
 func (t *Table) IsPrintSql(is bool) *Table {
 	t.isPrintSql = is
@@ -137,7 +248,10 @@ func (t *Table) PrintSqlCallSkip(skip uint8) *Table {
 
 // Clone 克隆一个新的 Table 对象
 func (t *Table) Clone() *Table {
-	newT := NewTable(t.db, t.name).
+	newT := new(Table)
+	newT.engine = t.engine
+	newT.Reset()
+	newT.initDb(t.db, t.name).
 		Ctx(t.ctx).
 		DbType(t.dbType)
 	if t.builder == nil {
@@ -145,6 +259,11 @@ func (t *Table) Clone() *Table {
 		t.err = internal.BuilderIsNilErr
 		return t
 	}
+	// 克隆后为原生 sql, 需要先添加软删除条件
+	if err := t.applySoftDeleteFilter(); err != nil {
+		t.err = err
+		return t
+	}
 	sqlStr, args := t.builder.GetNoParseSql2Args()
 	_, bld := parseSQLBuilder(t.dbType, sqlStr, args...)
 	newT.builder = bld
@@ -230,15 +349,18 @@ func (t *Table) initCacheCol2InfoMap() error {
 	tableName := parseTableName(t.name)
 
 	// 先判断下缓存中有没有
-	if info, ok := cacheTableName2ColInfoMap.Load(tableName); ok {
+	if info, ok := t.getColInfoCache().Load(tableName); ok {
 		t.cacheCol2InfoMap, ok = info.(map[string]*dialect.TableColInfo)
 		if ok {
 			return nil
 		}
 	}
 
-	var err error
-	t.cacheCol2InfoMap, err = dialect.GetTableMeter(t.dbType).GetColInfoMap(t.ctx, t.db, tableName)
+	tableMeter, err := dialect.GetTableMeter(t.dbType)
+	if err != nil {
+		return err
+	}
+	t.cacheCol2InfoMap, err = tableMeter.GetColInfoMap(t.ctx, t.db, tableName)
 	if err != nil {
 		return err
 	}
@@ -247,7 +369,7 @@ func (t *Table) initCacheCol2InfoMap() error {
 	}
 
 	// 缓存
-	cacheTableName2ColInfoMap.Store(tableName, t.cacheCol2InfoMap)
+	t.getColInfoCache().Store(tableName, t.cacheCol2InfoMap)
 	return nil
 }
 
@@ -329,54 +451,136 @@ func (t *Table) parseCol2StructField(ty reflect.Type, isNeedSort bool) (col2Stru
 	// 通过地址来取, 防止出现重复
 	// 当 t.waitHandleStructFieldMap != nil 不等于空时, 为了防止解析 selectFields 缺少, 不能走缓存中取
 	if t.waitHandleStructFieldMap == nil {
-		if cacheVal, ok := cacheStructType2StructFieldMap.Load(ty); ok { // 需要排除再包含 t.waitHandleStructFieldMap 不为空的
+		if cacheVal, ok := t.getStructFieldCache().Load(ty); ok { // 需要排除再包含 t.waitHandleStructFieldMap 不为空的
 			col2StructFieldMap = cacheVal.(map[string]structField)
-			if isNeedSort { // 按照col2FieldIndexMap的value进行排序
-				l := len(col2StructFieldMap)
-				tmpMap := make(map[int]string, l)
-				tmpSortVal := make([]int, 0, l)
-				for col, field := range col2StructFieldMap {
-					tmpMap[field.offsetIndex] = col
-					tmpSortVal = append(tmpSortVal, field.offsetIndex)
-				}
-				sort.Ints(tmpSortVal)
-				sortCol = make([]string, 0, l)
-				for _, fieldIndex := range tmpSortVal {
-					sortCol = append(sortCol, tmpMap[fieldIndex])
+			if isNeedSort { // 按照字段的索引路径进行排序
+				sortCol = make([]string, 0, len(col2StructFieldMap))
+				for col := range col2StructFieldMap {
+					sortCol = append(sortCol, col)
 				}
+				sort.Slice(sortCol, func(i, j int) bool {
+					return lessIndex(col2StructFieldMap[sortCol[i]].index, col2StructFieldMap[sortCol[j]].index)
+				})
 			}
 			return
 		}
 	}
 
-	fieldNum := ty.NumField()
-	col2StructFieldMap = make(map[string]structField, fieldNum)
-	sortCol = make([]string, 0, fieldNum)
-	for i := 0; i < fieldNum; i++ {
-		col, tag, _ := t.parseStructField(ty.Field(i), sureUnmarshal)
+	fieldPaths := t.getStructFieldPaths(ty)
+	col2StructFieldMap = make(map[string]structField, len(fieldPaths))
+	sortCol = make([]string, 0, len(fieldPaths))
+	for _, fieldPath := range fieldPaths {
+		col, tag, _ := t.parseStructField(fieldPath.field, sureUnmarshal)
 		if utils.Null(col) {
 			continue
 		}
 
 		col2StructFieldMap[col] = structField{
-			offsetIndex: i,
-			tagName:     tag,
+			index:   fieldPath.index,
+			tagName: tag,
 		}
 		sortCol = append(sortCol, col)
 	}
 
 	if t.waitHandleStructFieldMap == nil {
-		cacheStructType2StructFieldMap.Store(ty, col2StructFieldMap)
+		t.getStructFieldCache().Store(ty, col2StructFieldMap)
 	}
 	return
 }
 
+// getStructFieldPaths 获取结构体的字段, 匿名嵌套的结构体(包含指针)会展开为其字段
+// 展开后 tag 同名时外层的字段优先, 返回的字段按索引路径排序
+func (t *Table) getStructFieldPaths(ty reflect.Type) []structFieldPath {
+	key := structFieldPathKey{ty: ty, tag: t.tag}
+	if cacheVal, ok := t.getStructFieldCache().Load(key); ok {
+		return cacheVal.([]structFieldPath)
+	}
+
+	var (
+		fieldPaths  = make([]structFieldPath, 0, ty.NumField())
+		removed     = make(map[int]bool)
+		tag2PathMap = make(map[string]int) // key: tag, value: fieldPaths 的下标
+		visited     = map[reflect.Type]bool{ty: true}
+		walk        func(ty reflect.Type, parent []int)
+	)
+	walk = func(ty reflect.Type, parent []int) {
+		for i := 0; i < ty.NumField(); i++ {
+			field := ty.Field(i)
+			index := make([]int, len(parent)+1)
+			copy(index, parent)
+			index[len(parent)] = i
+
+			if t.isEmbeddedStruct(field) {
+				embeddedTy := utils.RemoveTypePtr(field.Type)
+				if visited[embeddedTy] { // 防止循环嵌套
+					continue
+				}
+				visited[embeddedTy] = true
+				walk(embeddedTy, index)
+				delete(visited, embeddedTy)
+				continue
+			}
+
+			if tag := utils.ParseTag2Col(field.Tag.Get(t.tag)); tag != "" {
+				if j, ok := tag2PathMap[tag]; ok {
+					if len(fieldPaths[j].index) <= len(index) {
+						continue
+					}
+					removed[j] = true
+				}
+				tag2PathMap[tag] = len(fieldPaths)
+			}
+			fieldPaths = append(fieldPaths, structFieldPath{index: index, field: field})
+		}
+	}
+	walk(ty, nil)
+
+	if len(removed) > 0 {
+		tmp := make([]structFieldPath, 0, len(fieldPaths)-len(removed))
+		for i, fieldPath := range fieldPaths {
+			if !removed[i] {
+				tmp = append(tmp, fieldPath)
+			}
+		}
+		fieldPaths = tmp
+	}
+	t.getStructFieldCache().Store(key, fieldPaths)
+	return fieldPaths
+}
+
+// isEmbeddedStruct 是否为需要展开的匿名嵌套结构体, 如: BaseModel, *BaseModel
+// 注: 设置了 tag 的按普通字段处理, 不可导出的指针无法初始化也不展开
+func (t *Table) isEmbeddedStruct(field reflect.StructField) bool {
+	if !field.Anonymous || field.Tag.Get(t.tag) != "" {
+		return false
+	}
+	if utils.RemoveTypePtr(field.Type).Kind() != reflect.Struct {
+		return false
+	}
+	return utils.IsExported(field.Name) || field.Type.Kind() != reflect.Ptr
+}
+
+// lessIndex 比较索引路径
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
 // parseStructField 从结构体的 tag 中解析出列名, 同时跳过嵌套, 包含: 对象, 指针对象, 切片, 不可导出字段
 func (t *Table) parseStructField(fieldInfo reflect.StructField, args ...uint8) (col, tag string, need bool) {
 	if !utils.IsExported(fieldInfo.Name) {
 		return
 	}
 
+	// 关联字段不是表的列, 由 Preload 处理
+	if fieldInfo.Tag.Get(internal.RelTag) != "" {
+		return
+	}
+
 	// 解析 tag 中的列名
 	tag = fieldInfo.Tag.Get(t.tag)
 	if utils.Null(tag) {
@@ -473,6 +677,12 @@ func (t *Table) prevCheck(checkBuilder ...bool) error {
 		return errors.New("db is nil")
 	}
 
+	if t.builder != nil {
+		if err := t.builder.Err(); err != nil {
+			return err
+		}
+	}
+
 	switch b := t.builder.(type) {
 	case *builder.Delete:
 		// 需要校验是否设置了 where 条件, 防止误删
@@ -0,0 +1,235 @@
+package spellsql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gitee.com/xuesongtao/spellsql/v2/builder"
+	"gitee.com/xuesongtao/spellsql/v2/dialect"
+	"gitee.com/xuesongtao/spellsql/v2/internal"
+)
+
+// BatchErr 分批执行时单个批次的错误
+type BatchErr struct {
+	Start int // 批次在对象中的起始下标
+	End   int // 批次在对象中的结束下标(不包含)
+	Err   error
+}
+
+func (e *BatchErr) Error() string {
+	return fmt.Sprintf("batch [%d, %d) is failed, err: %v", e.Start, e.End, e.Err)
+}
+
+func (e *BatchErr) Unwrap() error {
+	return e.Err
+}
+
+// BatchResult 分批执行的结果
+type BatchResult struct {
+	RowsAffected int64       // 所有成功批次影响的行数之和
+	Errs         []*BatchErr // 失败的批次
+}
+
+// Err 返回失败批次的错误, 全部成功时返回 nil
+func (r *BatchResult) Err() error {
+	if len(r.Errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d batches is failed, first %w", len(r.Errs), r.Errs[0])
+}
+
+// BatchInTx 分批执行时是否在同一个事务中执行, 开启后任一批次失败会回滚所有批次
+// 注: db 为 *Tx/*sql.Tx 时通过保存点实现, 见 WithTx
+func (t *Table) BatchInTx(is bool) *Table {
+	t.batchInTx = is
+	return t
+}
+
+// InsertBatch 分批新增, 每批执行一条 INSERT, 防止数据量大时超过占位符数(65535)或 max_allowed_packet 的限制
+// batchSize <= 0 时默认 internal.DefaultBatchInsertSize, 每批的占位符数(列数 * 行数)超过 internal.MaxPlaceholderNum 时会自动缩小批次
+// 未开启 BatchInTx 时, 某个批次失败不影响其他批次, 失败的批次记录在 BatchResult.Errs 中
+// 注: 返回的 BatchResult 不会为 nil
+func (t *Table) InsertBatch(insertObjs []any, batchSize int) (*BatchResult, error) {
+	t.callInfo = getCallInfo(int(t.printSqlCallSkip)) // 每批打印 sql 时都显示调用 InsertBatch 的位置
+	res := &BatchResult{}
+	if t.err != nil {
+		return res, t.err
+	}
+	if len(insertObjs) == 0 {
+		return res, errors.New("insertObjs is empty")
+	}
+
+	cols, _, err := t.getHandleTableCol2Val(insertObjs[0], internal.INSERT, t.getNeedCols(insertObjs[0], nil))
+	if err != nil {
+		return res, errors.New("getHandleTableCol2Val is failed, err:" + err.Error())
+	}
+	batchSize = getBatchSize(batchSize, len(cols))
+	return res, t.execBatch(res, len(insertObjs), batchSize, func(start, end int) (sql.Result, error) {
+		if _, err := t.insert(internal.INSERT, nil, insertObjs[start:end]...); err != nil {
+			return nil, err
+		}
+		return t.Exec()
+	})
+}
+
+// UpdateBatch 批量更新, 每个对象更新的值可以不同, keyCol 为定位行的列(一般为主键), 生成的 sql 如:
+//
+//	UPDATE man SET `name` = CASE `id` WHEN 1 THEN "a" WHEN 2 THEN "b" ELSE `name` END WHERE `id` IN (1, 2)
+//
+// 更新的列同 Update, 对象中为零值的列保持原值; batchSize 默认 internal.DefaultBatchInsertSize, 分批和事务同 InsertBatch
+// 每个对象更新的列可能不同, 所以按表的列数计算每批的占位符数
+// 注: 不支持乐观锁, 设置了 Version 时会返回错误
+func (t *Table) UpdateBatch(updateObjs []any, keyCol string, batchSize ...int) (*BatchResult, error) {
+	t.callInfo = getCallInfo(int(t.printSqlCallSkip)) // 同 InsertBatch
+	res := &BatchResult{}
+	if t.err != nil {
+		return res, t.err
+	}
+	if len(updateObjs) == 0 {
+		return res, errors.New("updateObjs is empty")
+	}
+	if t.versionCol != "" {
+		return res, errors.New("update batch is not support version")
+	}
+
+	if err := t.initTableName(reflect.ValueOf(updateObjs[0])).initCacheCol2InfoMap(); err != nil {
+		return res, err
+	}
+	if _, ok := t.cacheCol2InfoMap[keyCol]; !ok {
+		return res, fmt.Errorf("key col %q is not exist in table %s", keyCol, t.name)
+	}
+
+	size := 0
+	if len(batchSize) > 0 {
+		size = batchSize[0]
+	}
+	size = getBatchSize(size, 2*len(t.cacheCol2InfoMap)+1) // 每行最多: 每列 WHEN ? THEN ?, 加上 IN 中的 ?
+	return res, t.execBatch(res, len(updateObjs), size, func(start, end int) (sql.Result, error) {
+		if err := t.setUpdateBatch(updateObjs[start:end], keyCol); err != nil {
+			return nil, err
+		}
+		return t.Exec()
+	})
+}
+
+// setUpdateBatch 生成批量更新的 builder
+func (t *Table) setUpdateBatch(updateObjs []any, keyCol string) error {
+	updateDialect, err := dialect.GetDialect(t.dbType)
+	if err != nil {
+		return err
+	}
+
+	var (
+		cols        []string
+		col2ArgsMap = make(map[string][]any) // 每列的 CASE 参数, 如: [key1, val1, key2, val2]
+		keys        = make([]any, 0, len(updateObjs))
+		warpKeyCol  = dialect.WarpCol(updateDialect, keyCol)
+	)
+	for _, updateObj := range updateObjs {
+		updateObj, err := t.callBeforeUpdate(updateObj)
+		if err != nil {
+			return err
+		}
+		columns, values, err := t.getHandleTableCol2Val(updateObj, internal.UPDATE, nil)
+		if err != nil {
+			return errors.New("getHandleTableCol2Val is failed, err:" + err.Error())
+		}
+		keyVal, ok := t.getStructFieldByCol(updateObj, keyCol)
+		if !ok {
+			return fmt.Errorf("key col %q is not found in struct", keyCol)
+		}
+		if keyVal.IsZero() {
+			return fmt.Errorf("key col %q is zero", keyCol)
+		}
+
+		key := keyVal.Interface()
+		keys = append(keys, key)
+		for i, col := range columns {
+			if col == keyCol {
+				continue
+			}
+			if _, ok := col2ArgsMap[col]; !ok {
+				cols = append(cols, col)
+			}
+			col2ArgsMap[col] = append(col2ArgsMap[col], key, values[i])
+		}
+	}
+	if len(cols) == 0 {
+		return internal.StructTagErr
+	}
+
+	updateBuilder := builder.NewUpdate(t.dbType).Table(t.name)
+	for _, col := range cols {
+		args := col2ArgsMap[col]
+		expr := "CASE " + warpKeyCol + strings.Repeat(" WHEN ? THEN ?", len(args)/2) + " ELSE " + dialect.WarpCol(updateDialect, col) + " END"
+		updateBuilder.SetExpr(col, expr, args...)
+	}
+	updateBuilder.WhereCb(func(wb *builder.Where) {
+		wb.In(keyCol, keys)
+	})
+	t.builder = updateBuilder
+	return nil
+}
+
+// getBatchSize 获取每批的条数, 保证每批的占位符数不超过 internal.MaxPlaceholderNum
+func getBatchSize(batchSize, colNum int) int {
+	if batchSize <= 0 {
+		batchSize = internal.DefaultBatchInsertSize
+	}
+	if colNum > 0 && batchSize*colNum > internal.MaxPlaceholderNum {
+		batchSize = internal.MaxPlaceholderNum / colNum
+	}
+	if batchSize == 0 {
+		batchSize = 1
+	}
+	return batchSize
+}
+
+// execBatch 按 batchSize 分批调用 fn, fn 执行 [start, end) 的批次
+func (t *Table) execBatch(res *BatchResult, total, batchSize int, fn func(start, end int) (sql.Result, error)) error {
+	defer func() { t.callInfo = nil }()
+
+	if !t.batchInTx {
+		t.execBatchChunks(res, total, batchSize, false, fn)
+		return res.Err()
+	}
+
+	db := t.db
+	defer func() { t.db = db }()
+	err := WithTx(t.ctx, db, func(tx DBer) error {
+		t.db = tx
+		return t.execBatchChunks(res, total, batchSize, true, fn)
+	}, t.dbType)
+	if err != nil {
+		res.RowsAffected = 0 // 已回滚
+		return err
+	}
+	return nil
+}
+
+// execBatchChunks 依次执行每个批次, failFast 为 true 时遇到错误就返回
+func (t *Table) execBatchChunks(res *BatchResult, total, batchSize int, failFast bool, fn func(start, end int) (sql.Result, error)) error {
+	for start := 0; start < total; start += batchSize {
+		end := start + batchSize
+		if end > total {
+			end = total
+		}
+
+		sqlRes, err := fn(start, end)
+		if err == nil {
+			var affected int64
+			affected, err = sqlRes.RowsAffected()
+			res.RowsAffected += affected
+		}
+		if err != nil {
+			res.Errs = append(res.Errs, &BatchErr{Start: start, End: end, Err: err})
+			if failFast {
+				return res.Err()
+			}
+		}
+	}
+	return nil
+}
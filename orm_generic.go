@@ -0,0 +1,110 @@
+package spellsql
+
+import (
+	"context"
+	"reflect"
+
+	"gitee.com/xuesongtao/spellsql/v2/builder"
+	"gitee.com/xuesongtao/spellsql/v2/utils"
+)
+
+// TypedQuery 泛型查询, 在编译期确定查询结果的类型, 内部使用 Table 实现
+// T 支持结构体和结构体指针, 如: Query[User](db), Query[*User](db)
+type TypedQuery[T any] struct {
+	table *Table
+}
+
+// Query 创建泛型查询, args 同 NewTable
+// 没有指定表名时, 通过 T 解析表名(实现了 TableNamer 使用其返回值, 否则按驼峰转下划线), 查询字段为 T 中表存在的字段
+// 如: users, err := Query[User](db).Where("age > ?", 18).All(ctx)
+func Query[T any](db DBer, args ...string) *TypedQuery[T] {
+	return newTypedQuery[T](NewTable(db, args...))
+}
+
+// EngineQuery 通过 Engine 创建泛型查询, 用法同 Query
+func EngineQuery[T any](e *Engine, args ...string) *TypedQuery[T] {
+	return newTypedQuery[T](e.NewTable(args...))
+}
+
+func newTypedQuery[T any](t *Table) *TypedQuery[T] {
+	ty := utils.RemoveTypePtr(reflect.TypeFor[T]())
+	t.SelectAuto(reflect.New(ty).Interface())
+	return &TypedQuery[T]{table: t}
+}
+
+// Table 获取内部的 Table, 用于调用 TypedQuery 没有提供的方法
+func (q *TypedQuery[T]) Table() *Table {
+	return q.table
+}
+
+// Where 添加条件, 同 Table.Where
+func (q *TypedQuery[T]) Where(sqlStr string, args ...any) *TypedQuery[T] {
+	q.table.Where(sqlStr, args...)
+	return q
+}
+
+// OrWhere 添加 OR 条件, 同 Table.OrWhere
+func (q *TypedQuery[T]) OrWhere(sqlStr string, args ...any) *TypedQuery[T] {
+	q.table.OrWhere(sqlStr, args...)
+	return q
+}
+
+// WhereNewGroup 添加一组条件, 同 Table.WhereNewGroup
+func (q *TypedQuery[T]) WhereNewGroup(f func(wb *builder.Where)) *TypedQuery[T] {
+	q.table.WhereNewGroup(f)
+	return q
+}
+
+// OrderBy 排序, 同 Table.OrderBy
+func (q *TypedQuery[T]) OrderBy(sqlStr string) *TypedQuery[T] {
+	q.table.OrderBy(sqlStr)
+	return q
+}
+
+// Limit 分页, 同 Table.Limit
+func (q *TypedQuery[T]) Limit(page, size any) *TypedQuery[T] {
+	q.table.Limit(page, size)
+	return q
+}
+
+// Preload 预加载关联字段, 同 Table.Preload
+func (q *TypedQuery[T]) Preload(fields ...string) *TypedQuery[T] {
+	q.table.Preload(fields...)
+	return q
+}
+
+// All 查询多行
+func (q *TypedQuery[T]) All(ctx context.Context) ([]T, error) {
+	var res []T
+	q.table.printSqlCallSkip++
+	if err := q.table.Ctx(ctx).FindAll(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// One 查询单行, 为空时返回的 err 可以通过 IsNullRow 判断
+func (q *TypedQuery[T]) One(ctx context.Context) (T, error) {
+	var res T
+	q.table.printSqlCallSkip++
+	if err := q.table.Ctx(ctx).FindOne(&res); err != nil {
+		var zero T
+		return zero, err
+	}
+	return res, nil
+}
+
+// Iter 逐行遍历, 不会将结果集全部加载到内存中, yield 返回 false 时终止遍历
+// 如(go1.23+): for user, err := range Query[User](db).Iter(ctx) {}
+func (q *TypedQuery[T]) Iter(ctx context.Context) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		q.table.printSqlCallSkip++
+		rows, err := q.table.Ctx(ctx).Rows()
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		RowsSeq[T](rows)(yield)
+	}
+}
@@ -3,6 +3,7 @@ package spellsql
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
 	"sort"
 	"time"
@@ -65,6 +66,10 @@ func (t *Table) insert(opType internal.OpType, cols []string, insertObjs ...any)
 		// if isOnlyInsert { // insert 一个值的时候, 在解析列的时候跳过零值
 		// 	needCols = nil
 		// }
+		insertObj, err := t.callBeforeInsert(insertObj)
+		if err != nil {
+			return nil, err
+		}
 		columns, values, err := t.getHandleTableCol2Val(insertObj, opType, needCols)
 		if err != nil {
 			return nil, errors.New("getHandleTableCol2Val is failed, err:" + err.Error())
@@ -85,9 +90,69 @@ func (t *Table) insert(opType internal.OpType, cols []string, insertObjs ...any)
 		insertSql.Values(values...)
 	}
 	t.builder = insertSql
+	t.handleCols = handleCols
+	t.insertOp = opType
+	t.insertObjs = insertObjs
 	return handleCols, nil
 }
 
+// Returning 插入后返回 cols 的值, 并回填到 Insert 的对象中(对象需要为指针), 默认返回主键
+// 如: NewTable(db).Insert(&man1, &man2).Returning().Exec()
+// 注:
+//
+//	1.仅支持 Postgres/SQLite/SQLServer, 需要在 Insert 后调用
+//	2.批量插入时按返回的顺序依次回填, 数据库不保证返回的顺序和插入的顺序一致(如: Postgres), 需要准确对应时请逐条插入
+//	3.仅支持普通的 Insert, InsertsIg/InsertsRp/InsertsODKU/Upsert 冲突时返回的行数会少于插入的行数, 无法按顺序回填
+func (t *Table) Returning(cols ...string) *Table {
+	if t.err != nil {
+		return t
+	}
+
+	insertBuilder, ok := t.builder.(*builder.Insert)
+	if !ok {
+		t.err = errors.New("returning should call after insert")
+		return t
+	}
+	if t.insertOp != internal.INSERT {
+		t.err = errors.New("returning only support insert, it is not support insert ignore/replace/upsert")
+		return t
+	}
+
+	switch t.dbType {
+	case dialect.Postgres, dialect.SQLite, dialect.SQLServer:
+	default:
+		t.err = fmt.Errorf("db type %s is not support returning", t.dbType)
+		return t
+	}
+
+	if len(cols) == 0 {
+		cols = t.getPriCols()
+		if len(cols) == 0 {
+			t.err = fmt.Errorf("table %s no have primary key", t.name)
+			return t
+		}
+	}
+	insertBuilder.Returning(cols...)
+	t.returningCols = cols
+	return t
+}
+
+// getPriCols 获取主键列, 按表中的顺序
+func (t *Table) getPriCols() []string {
+	infos := make([]*dialect.TableColInfo, 0, 1)
+	for _, info := range t.cacheCol2InfoMap {
+		if info.IsPri() {
+			infos = append(infos, info)
+		}
+	}
+	sort.Sort(dialect.SortByTableColInfo(infos))
+	cols := make([]string, len(infos))
+	for i, info := range infos {
+		cols[i] = info.Field
+	}
+	return cols
+}
+
 // getNeedCols 获取需要 cols
 func (t *Table) getNeedCols(src any, cols []string) map[string]bool {
 	if len(cols) == 0 {
@@ -110,13 +175,17 @@ func (t *Table) InsertODKU(insertObj any, keys ...string) *Table {
 // InsertsODKU insert 主键冲突更新批量
 // 如果要排除其他可以调用 Exclude 方法自定义排除
 // keys 为需要更新的列, 如果不传则默认更新所有列
+// 注: 会根据 dbType 生成对应的语法, 冲突列为主键, 更多设置见 Upsert
 func (t *Table) InsertsODKU(insertObjs []any, keys ...string) *Table {
-	if _, err := t.insert(internal.INSERT_ON_DUPLICATE, nil, insertObjs...); err != nil {
+	handleCols, err := t.insert(internal.INSERT_ON_DUPLICATE, nil, insertObjs...)
+	if err != nil {
 		// sLog.Error(t.ctx, err)
 		t.err = err
 		return t
 	}
-	t.builder.(*builder.Insert).DuplicateUpdate(keys)
+	if err := t.setUpsert(handleCols, UpsertOpts{UpdateCols: keys}); err != nil {
+		t.err = err
+	}
 	return t
 }
 
@@ -135,30 +204,71 @@ func (t *Table) InsertIg(insertObj any) *Table {
 // InsertsIg insert ignore into xxx  新增批量忽略
 // 如果要排除其他可以调用 Exclude 方法自定义排除
 func (t *Table) InsertsIg(insertObjs ...any) *Table {
-	if _, err := t.insert(internal.INSERT_IGNORE, nil, insertObjs...); err != nil {
+	handleCols, err := t.insert(internal.INSERT_IGNORE, nil, insertObjs...)
+	if err != nil {
 		// sLog.Error(t.ctx, err)
 		t.err = err
 		return t
 	}
+	if err := t.convInsertIgRp(internal.INSERT_IGNORE, handleCols); err != nil {
+		t.err = err
+	}
 	return t
 }
 
 // InsertsRp insert replace into xxx  新增批量替换
 // 如果要排除其他可以调用 Exclude 方法自定义排除
 func (t *Table) InsertsRp(insertObjs ...any) *Table {
-	if _, err := t.insert(internal.INSERT_REPLACE, nil, insertObjs...); err != nil {
+	handleCols, err := t.insert(internal.INSERT_REPLACE, nil, insertObjs...)
+	if err != nil {
 		// sLog.Error(t.ctx, err)
 		t.err = err
 		return t
 	}
+	if err := t.convInsertIgRp(internal.INSERT_REPLACE, handleCols); err != nil {
+		t.err = err
+	}
 	return t
 }
 
+// convInsertIgRp 不支持 INSERT IGNORE/REPLACE 的数据库, 转为对应的冲突处理:
+//
+//	MySQL/SQLite: 原生支持, 不处理(SQLite 的 IGNORE 为 INSERT OR IGNORE)
+//	Postgres: IGNORE 为 ON CONFLICT DO NOTHING, REPLACE 为 ON CONFLICT (主键) DO UPDATE SET 除主键外新增的列
+//	其他: 方言实现了 dialect.Upserter 时, 同 Upsert 处理, 否则返回错误
+func (t *Table) convInsertIgRp(opType internal.OpType, handleCols []string) error {
+	switch t.dbType {
+	case dialect.MySQL, dialect.SQLite:
+		return nil
+	case dialect.Postgres:
+		if opType == internal.INSERT_IGNORE { // builder 中已处理
+			return nil
+		}
+	}
+
+	opName := "insert ignore"
+	opt := UpsertOpts{DoNothing: true}
+	if opType == internal.INSERT_REPLACE {
+		opName = "insert replace"
+		opt = UpsertOpts{}
+	}
+	t.builder.(*builder.Insert).Into(t.name)
+	if err := t.setUpsert(handleCols, opt); err != nil {
+		return fmt.Errorf("db type %s is not support %s, err: %v", t.dbType, opName, err)
+	}
+	return nil
+}
+
 // Delete 会以对象中有值得为条件进行删除
 // 如果要排除其他可以调用 Exclude 方法自定义排除
 func (t *Table) Delete(deleteObj ...any) *Table {
 	if len(deleteObj) > 0 {
-		columns, values, err := t.getHandleTableCol2Val(deleteObj[0], internal.DELETE, nil)
+		obj, err := t.callBeforeDelete(deleteObj[0])
+		if err != nil {
+			t.err = err
+			return t
+		}
+		columns, values, err := t.getHandleTableCol2Val(obj, internal.DELETE, nil)
 		if err != nil {
 			// sLog.Error(t.ctx, "getHandleTableCol2Val is failed, err:", err)
 			t.err = err
@@ -177,7 +287,7 @@ func (t *Table) Delete(deleteObj ...any) *Table {
 		t.builder = bld
 	} else {
 		if utils.Null(t.name) {
-			sLog.Error(t.ctx, internal.TableNameIsUnknownErr)
+			t.getLogger().Error(t.ctx, internal.TableNameIsUnknownErr)
 			return t
 		}
 		t.builder = builder.NewDelete(t.dbType).From(t.name)
@@ -193,6 +303,11 @@ func (t *Table) DeleteWhere(where string, args ...any) *Table {
 // Update 会更新输入的值
 // 默认排除更新主键, 如果要排除其他可以调用 Exclude 方法自定义排除
 func (t *Table) Update(updateObj any, where string, args ...any) *Table {
+	updateObj, err := t.callBeforeUpdate(updateObj)
+	if err != nil {
+		t.err = err
+		return t
+	}
 	columns, values, err := t.getHandleTableCol2Val(updateObj, internal.UPDATE, nil)
 	if err != nil {
 		// sLog.Error(t.ctx, "getHandleTableCol2Val is failed, err:", err)
@@ -205,12 +320,21 @@ func (t *Table) Update(updateObj any, where string, args ...any) *Table {
 	for i := 0; i < l; i++ {
 		k := columns[i]
 		v := values[i]
+		if k == t.versionCol { // 版本号由乐观锁处理
+			continue
+		}
 		// t.tmpSqlObj.SetUpdateValueArgs("?v = ?", t.GetParcelFields(k), v)
 		updateBuilder.Set(k, v)
 	}
 	updateBuilder.WhereCb(func(wb *builder.Where) {
 		wb.And(where, args...)
 	})
+	if t.versionCol != "" {
+		if err := t.setVersionLock(updateObj, updateBuilder); err != nil {
+			t.err = err
+			return t
+		}
+	}
 	t.builder = updateBuilder
 	return t
 }
@@ -229,12 +353,24 @@ func (t *Table) getHandleTableCol2Val(v any, op uint8, needCols map[string]bool)
 		return nil, nil, err
 	}
 
-	ty := tv.Type()
-	fieldNum := ty.NumField()
-	columns = make([]string, 0, fieldNum)
-	values = make([]any, 0, fieldNum)
-	for i := 0; i < fieldNum; i++ {
-		col, tag, needMarshal := t.parseStructField(ty.Field(i), sureMarshal)
+	var (
+		fieldPaths = t.getStructFieldPaths(tv.Type())
+		fieldNum   = len(fieldPaths)
+		autoCols   = t.getAutoTimestampCols(op)
+		autoMap    map[string]bool // 待处理的自动时间列
+		now        time.Time
+	)
+	if len(autoCols) > 0 {
+		now = t.now()
+		autoMap = make(map[string]bool, len(autoCols))
+		for _, col := range autoCols {
+			autoMap[col] = true
+		}
+	}
+	columns = make([]string, 0, fieldNum+len(autoCols))
+	values = make([]any, 0, fieldNum+len(autoCols))
+	for _, fieldPath := range fieldPaths {
+		col, tag, needMarshal := t.parseStructField(fieldPath.field, sureMarshal)
 		if utils.Null(col) {
 			continue
 		}
@@ -246,9 +382,22 @@ func (t *Table) getHandleTableCol2Val(v any, op uint8, needCols map[string]bool)
 			continue
 		}
 
-		// 空值处理
-		val := tv.Field(i)
+		// 空值处理, 匿名嵌套的指针为 nil 时按零值处理
+		val, ok := utils.FieldByIndex(tv, fieldPath.index)
+		if !ok {
+			val = reflect.Zero(fieldPath.field.Type)
+		}
 		isZero := val.IsZero()
+
+		// 自动时间列, insert 时零值才填充, update 时总是刷新
+		if autoMap[col] {
+			delete(autoMap, col)
+			if isZero || op == internal.UPDATE {
+				columns = append(columns, col)
+				values = append(values, now)
+				continue
+			}
+		}
 		insertOp := internal.InArray(op, internal.INSERT, internal.INSERT_REPLACE, internal.INSERT_IGNORE, internal.INSERT_ON_DUPLICATE)
 		if tableField.IsPri() { // 主键, 防止更新
 			if (insertOp && isZero) ||
@@ -268,7 +417,11 @@ func (t *Table) getHandleTableCol2Val(v any, op uint8, needCols map[string]bool)
 					continue
 				} else if needCols != nil && needCols[col] { // 需要的列, 使用数据库默认值
 					columns = append(columns, col)
-					values = append(values, dialect.GetTableMeter(t.dbType).GetDefaultVal(col, tableField))
+					tableMeter, err := dialect.GetTableMeter(t.dbType)
+					if err != nil {
+						return nil, nil, err
+					}
+					values = append(values, tableMeter.GetDefaultVal(col, tableField))
 					continue
 				}
 				// if tableField.NotNull() && !tableField.Default.Valid && !ok { // db 中没有设置默认值
@@ -298,6 +451,15 @@ func (t *Table) getHandleTableCol2Val(v any, op uint8, needCols map[string]bool)
 		}
 	}
 
+	// 结构体中没有的自动时间列
+	for _, col := range autoCols {
+		if !autoMap[col] || (needCols != nil && !needCols[col]) {
+			continue
+		}
+		columns = append(columns, col)
+		values = append(values, now)
+	}
+
 	if len(columns) == 0 || len(values) == 0 {
 		err = internal.StructTagErr
 		return
@@ -336,7 +498,7 @@ func (t *Table) GetCols(skipCols ...string) []string {
 		}
 	}
 	if err := t.initCacheCol2InfoMap(); err != nil {
-		sLog.Error(t.ctx, "t.initCacheCol2InfoMap is failed, err:", err)
+		t.getLogger().Error(t.ctx, "t.initCacheCol2InfoMap is failed, err:", err)
 		return nil
 	}
 	infos := make([]*dialect.TableColInfo, 0, len(t.cacheCol2InfoMap))
@@ -360,16 +522,178 @@ func (t *Table) Exec() (sql.Result, error) {
 	if err := t.prevCheck(); err != nil {
 		return nil, err
 	}
+	if err := t.convSoftDeleteBuilder(); err != nil {
+		return nil, err
+	}
 	after := &AfterHook{
 		St:       time.Now(),
 		Builder:  t.builder,
-		CallInfo: getCallInfo(int(t.printSqlCallSkip)),
+		CallInfo: t.callInfo,
+	}
+	if len(after.CallInfo) == 0 {
+		after.CallInfo = getCallInfo(int(t.printSqlCallSkip))
 	}
+	if len(t.returningCols) > 0 {
+		return t.execReturning(after)
+	}
+
 	sqlStr, args := t.builder.GetSql2Args()
 	res, err := t.db.ExecContext(t.ctx, sqlStr, args...)
 	if err != nil {
 		return res, errors.New("err:" + err.Error() + "; sqlStr:" + t.builder.GetSqlStr())
 	}
+	t.backfillAutoIncId(res)
+	t.afterHook(after)
+	if err := t.checkVersionLock(res); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// backfillAutoIncId mysql Insert 后, 通过 LastInsertId + 行偏移量回填指针对象的自增主键
+// 满足以下条件才会回填:
+//
+//	1.普通 INSERT(不包含 IGNORE/REPLACE/ON DUPLICATE KEY UPDATE, 它们的 LastInsertId 和行对应不上)
+//	2.自增主键没有在插入的列中, 即: 对象的主键都为零值
+//	3.批量插入时, autoIncLockMode 为 AutoIncLockModeTraditional/AutoIncLockModeConsecutive, 且影响行数等于对象数, 主键按 autoIncIncrement 递增
+func (t *Table) backfillAutoIncId(res sql.Result) {
+	objLen := len(t.insertObjs)
+	if !t.dbType.Is(dialect.MySQL) || t.insertOp != internal.INSERT || objLen == 0 {
+		return
+	}
+	if objLen > 1 && t.autoIncLockMode > AutoIncLockModeConsecutive {
+		return
+	}
+
+	var col string
+	for _, info := range t.cacheCol2InfoMap {
+		if info.IsPri() && info.IsAutoIncrement() {
+			col = info.Field
+			break
+		}
+	}
+	if col == "" {
+		return
+	}
+	for _, handleCol := range t.handleCols {
+		if handleCol == col {
+			return
+		}
+	}
+
+	firstId, err := res.LastInsertId()
+	if err != nil || firstId <= 0 {
+		return
+	}
+	if objLen > 1 {
+		if affected, err := res.RowsAffected(); err != nil || affected != int64(objLen) {
+			return
+		}
+	}
+
+	for i, obj := range t.insertObjs {
+		tv := reflect.ValueOf(obj)
+		if tv.Kind() != reflect.Ptr || tv.IsNil() || tv.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		tv = tv.Elem()
+		col2StructFieldMap, _ := t.parseCol2StructField(tv.Type(), false)
+		field, ok := col2StructFieldMap[col]
+		if !ok {
+			continue
+		}
+		fieldVal := utils.FieldByIndexAlloc(tv, field.index)
+		id := firstId + int64(i)*t.autoIncIncrement
+		switch fieldVal.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if !fieldVal.OverflowInt(id) {
+				fieldVal.SetInt(id)
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if !fieldVal.OverflowUint(uint64(id)) {
+				fieldVal.SetUint(uint64(id))
+			}
+		}
+	}
+}
+
+// execReturning 执行带有 RETURNING 的 Insert, 将返回的值回填到对象中
+func (t *Table) execReturning(after *AfterHook) (sql.Result, error) {
+	sqlStr, args := t.builder.GetSql2Args()
+	rows, err := t.db.QueryContext(t.ctx, sqlStr, args...)
+	if err != nil {
+		return nil, errors.New("err:" + err.Error() + "; sqlStr:" + t.builder.GetSqlStr())
+	}
+	defer rows.Close()
+
+	res := &returningResult{}
+	for rows.Next() {
+		var dest reflect.Value
+		if int(res.affected) < len(t.insertObjs) {
+			dest = reflect.ValueOf(t.insertObjs[res.affected])
+		}
+		values, firstVal := t.getReturningValues(dest)
+		if err := rows.Scan(values...); err != nil {
+			return nil, err
+		}
+		res.setLastId(firstVal)
+		res.affected++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	t.afterHook(after)
 	return res, nil
 }
+
+// getReturningValues 获取 returningCols 对应的 scan 对象, 如果 dest 为结构体指针, 会直接 scan 到对应的字段
+func (t *Table) getReturningValues(dest reflect.Value) (values []any, firstVal any) {
+	var col2StructFieldMap map[string]structField
+	if dest.Kind() == reflect.Ptr && !dest.IsNil() && dest.Elem().Kind() == reflect.Struct {
+		dest = dest.Elem()
+		col2StructFieldMap, _ = t.parseCol2StructField(dest.Type(), false)
+	}
+
+	values = make([]any, len(t.returningCols))
+	for i, col := range t.returningCols {
+		if field, ok := col2StructFieldMap[col]; ok {
+			values[i] = utils.FieldByIndexAlloc(dest, field.index).Addr().Interface()
+		} else {
+			values[i] = new(any)
+		}
+	}
+	return values, values[0]
+}
+
+// returningResult 实现 sql.Result
+type returningResult struct {
+	affected  int64
+	lastId    int64
+	lastIdErr error
+}
+
+// setLastId 记录 RETURNING 第一列的值
+func (r *returningResult) setLastId(val any) {
+	v := utils.RemoveValuePtr(reflect.ValueOf(val))
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	r.lastId, r.lastIdErr = 0, nil
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		r.lastId = v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		r.lastId = int64(v.Uint())
+	default:
+		r.lastIdErr = errors.New("returning first col is not int")
+	}
+}
+
+// LastInsertId 返回最后一行 RETURNING 第一列的值, 如果不是整数会返回错误
+func (r *returningResult) LastInsertId() (int64, error) {
+	return r.lastId, r.lastIdErr
+}
+
+func (r *returningResult) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
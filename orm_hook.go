@@ -0,0 +1,85 @@
+package spellsql
+
+import (
+	"context"
+	"reflect"
+
+	"gitee.com/xuesongtao/spellsql/v2/utils"
+)
+
+// BeforeInserter Insert 前对每个对象调用, 返回错误时会终止 Insert
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// BeforeUpdater Update 前调用, 返回错误时会终止 Update
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// BeforeDeleter Delete(传入对象时) 前调用, 返回错误时会终止 Delete
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+// AfterFinder 查询到结构体后对每行调用, 返回错误时会终止查询
+type AfterFinder interface {
+	AfterFind(ctx context.Context) error
+}
+
+var afterFinderType = reflect.TypeFor[AfterFinder]()
+
+// getHookObj 获取实现了 hook 的对象
+// 如果 obj 为值类型, 但是指针实现了 hook, 会拷贝一份再返回指针, 这样 hook 中修改的值才能生效
+func getHookObj[T any](obj any) (any, T, bool) {
+	if hook, ok := obj.(T); ok {
+		return obj, hook, true
+	}
+
+	var zero T
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Struct || !utils.CheckImplementation(v.Type(), reflect.TypeFor[T]()) {
+		return obj, zero, false
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	hook, ok := ptr.Interface().(T)
+	return ptr.Interface(), hook, ok
+}
+
+// callBeforeInsert 调用 BeforeInsert, 返回调用后的对象
+func (t *Table) callBeforeInsert(obj any) (any, error) {
+	obj, hook, ok := getHookObj[BeforeInserter](obj)
+	if !ok {
+		return obj, nil
+	}
+	return obj, hook.BeforeInsert(t.ctx)
+}
+
+// callBeforeUpdate 调用 BeforeUpdate, 返回调用后的对象
+func (t *Table) callBeforeUpdate(obj any) (any, error) {
+	obj, hook, ok := getHookObj[BeforeUpdater](obj)
+	if !ok {
+		return obj, nil
+	}
+	return obj, hook.BeforeUpdate(t.ctx)
+}
+
+// callBeforeDelete 调用 BeforeDelete, 返回调用后的对象
+func (t *Table) callBeforeDelete(obj any) (any, error) {
+	obj, hook, ok := getHookObj[BeforeDeleter](obj)
+	if !ok {
+		return obj, nil
+	}
+	return obj, hook.BeforeDelete(t.ctx)
+}
+
+// needAfterFind 查询结果是否需要调用 AfterFind, ty 为去指针后的类型
+func (t *Table) needAfterFind(ty reflect.Type) bool {
+	return t.isDestType(structFlag) && utils.CheckImplementation(ty, afterFinderType)
+}
+
+// callAfterFind 调用 AfterFind, base 为可取址的结构体
+func (t *Table) callAfterFind(base reflect.Value) error {
+	return base.Addr().Interface().(AfterFinder).AfterFind(t.ctx)
+}
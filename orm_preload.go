@@ -0,0 +1,333 @@
+package spellsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gitee.com/xuesongtao/spellsql/v2/builder"
+	"gitee.com/xuesongtao/spellsql/v2/internal"
+	"gitee.com/xuesongtao/spellsql/v2/utils"
+)
+
+// relation 关联关系, 通过结构体字段的 rel tag 解析, 如:
+//
+//	type User struct {
+//		Id      int32    `json:"id"`
+//		Orders  []Order  `rel:"has_many,foreign=user_id"`
+//		Profile *Profile `rel:"has_one,foreign=user_id"`
+//	}
+//	type Order struct {
+//		Id     int32 `json:"id"`
+//		UserId int32 `json:"user_id"`
+//		User   *User `rel:"belongs_to,foreign=user_id"`
+//	}
+//
+// foreign: has_one/has_many 时为子表的列, belongs_to 时为本表的列, 必填
+// references: has_one/has_many 时为本表的列, belongs_to 时为子表的列, 默认: id
+type relation struct {
+	kind       string
+	foreign    string
+	references string
+}
+
+// parentCol 本表中用于关联的列
+func (r *relation) parentCol() string {
+	if r.kind == internal.RelBelongsTo {
+		return r.foreign
+	}
+	return r.references
+}
+
+// childCol 子表中用于关联的列
+func (r *relation) childCol() string {
+	if r.kind == internal.RelBelongsTo {
+		return r.references
+	}
+	return r.foreign
+}
+
+// parseRelation 解析 rel tag
+func parseRelation(tag string) (*relation, error) {
+	rel := &relation{references: "id"}
+	for i, item := range strings.Split(tag, ",") {
+		item = strings.TrimSpace(item)
+		if i == 0 {
+			rel.kind = item
+			continue
+		}
+		k, v, _ := strings.Cut(item, "=")
+		switch strings.TrimSpace(k) {
+		case "foreign":
+			rel.foreign = strings.TrimSpace(v)
+		case "references":
+			rel.references = strings.TrimSpace(v)
+		}
+	}
+
+	switch rel.kind {
+	case internal.RelHasOne, internal.RelHasMany, internal.RelBelongsTo:
+	default:
+		return nil, fmt.Errorf("rel %q is not support, it should be has_one/has_many/belongs_to", rel.kind)
+	}
+	if rel.foreign == "" {
+		return nil, fmt.Errorf("rel %q foreign is empty", tag)
+	}
+	return rel, nil
+}
+
+// Preload 查询后预加载关联字段, fields 为结构体字段名, 支持多层, 如: Preload("Orders", "Orders.Items")
+// 每个关联字段会按关联值分批(每批最多 DefaultBatchInsertSize 个)执行 IN 查询, 字段需要设置 rel tag, 见 relation
+// 关联值支持指针和 sql.NullInt64 等 driver.Valuer, 为 nil 或零值时不会关联
+// 关联表会沿用当前 Table 的软删除设置(SoftDelete/Unscoped)
+// 注: 仅支持 FindOne/FindAll/FindWhere 等查询到结构体(切片)的方法
+func (t *Table) Preload(fields ...string) *Table {
+	t.preloads = append(t.preloads, fields...)
+	return t
+}
+
+// preload 预加载关联字段
+func (t *Table) preload(dest any) error {
+	if len(t.preloads) == 0 {
+		return nil
+	}
+
+	parents, ty := t.getPreloadParents(dest)
+	if len(parents) == 0 {
+		return nil
+	}
+
+	// 合并多层, 如: Orders, Orders.Items => Orders: [Items]
+	var (
+		fieldNames   = make([]string, 0, len(t.preloads))
+		field2SubMap = make(map[string][]string, len(t.preloads))
+	)
+	for _, preload := range t.preloads {
+		name, sub, _ := strings.Cut(preload, ".")
+		if _, ok := field2SubMap[name]; !ok {
+			fieldNames = append(fieldNames, name)
+			field2SubMap[name] = nil
+		}
+		if sub != "" {
+			field2SubMap[name] = append(field2SubMap[name], sub)
+		}
+	}
+
+	for _, name := range fieldNames {
+		if err := t.preloadField(parents, ty, name, field2SubMap[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getPreloadParents 获取查询结果中的结构体
+func (t *Table) getPreloadParents(dest any) ([]reflect.Value, reflect.Type) {
+	destValue := utils.RemoveValuePtr(reflect.ValueOf(dest))
+	switch destValue.Kind() {
+	case reflect.Struct:
+		return []reflect.Value{destValue}, destValue.Type()
+	case reflect.Slice:
+		ty := utils.RemoveTypePtr(destValue.Type().Elem())
+		if ty.Kind() != reflect.Struct {
+			return nil, nil
+		}
+		parents := make([]reflect.Value, 0, destValue.Len())
+		for i := 0; i < destValue.Len(); i++ {
+			elem := destValue.Index(i)
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					continue
+				}
+				elem = elem.Elem()
+			}
+			parents = append(parents, elem)
+		}
+		return parents, ty
+	}
+	return nil, nil
+}
+
+// preloadField 预加载单个关联字段
+func (t *Table) preloadField(parents []reflect.Value, ty reflect.Type, name string, subPreloads []string) error {
+	field, ok := ty.FieldByName(name)
+	if !ok {
+		return fmt.Errorf("preload field %q is not found in %s", name, ty)
+	}
+	rel, err := parseRelation(field.Tag.Get(internal.RelTag))
+	if err != nil {
+		return fmt.Errorf("preload field %q is failed, err: %v", name, err)
+	}
+
+	childTy := utils.RemoveTypePtr(field.Type)
+	if rel.kind == internal.RelHasMany {
+		if childTy.Kind() != reflect.Slice {
+			return fmt.Errorf("preload field %q should is slice", name)
+		}
+		childTy = utils.RemoveTypePtr(childTy.Elem())
+	}
+	if childTy.Kind() != reflect.Struct {
+		return fmt.Errorf("preload field %q should is struct", name)
+	}
+
+	// 收集本表的关联值, 去重
+	var (
+		keys      = make([]any, 0, len(parents))
+		keyExists = make(map[any]bool, len(parents))
+	)
+	for _, parent := range parents {
+		val, ok := t.getFieldValueByCol(parent, rel.parentCol())
+		if !ok {
+			return fmt.Errorf("preload field %q is failed, col %q is not found in %s", name, rel.parentCol(), ty)
+		}
+		key, ok, err := getRelKey(val)
+		if err != nil {
+			return fmt.Errorf("preload field %q is failed, err: %v", name, err)
+		}
+		if !ok || keyExists[key] {
+			continue
+		}
+		keyExists[key] = true
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// 分批查询子表, 防止 IN 的占位符超过限制
+	var (
+		children  = reflect.New(reflect.SliceOf(reflect.PointerTo(childTy))).Elem()
+		batchSize = getBatchSize(0, 1)
+	)
+	for start := 0; start < len(keys); start += batchSize {
+		end := start + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batchKeys := keys[start:end]
+		batchChildren := reflect.New(children.Type())
+		childTable := t.newRelTable()
+		childTable.SelectAuto(reflect.New(childTy).Interface()).getSelectBuilder().WhereCb(func(wb *builder.Where) {
+			wb.In(rel.childCol(), batchKeys)
+		})
+		if err := childTable.Preload(subPreloads...).FindAll(batchChildren.Interface()); err != nil {
+			return fmt.Errorf("preload field %q is failed, err: %v", name, err)
+		}
+		children = reflect.AppendSlice(children, batchChildren.Elem())
+	}
+
+	// 按关联值分组
+	key2ChildrenMap := make(map[any][]reflect.Value, len(keys))
+	for i := 0; i < children.Len(); i++ {
+		child := children.Index(i)
+		val, ok := t.getFieldValueByCol(child.Elem(), rel.childCol())
+		if !ok {
+			return fmt.Errorf("preload field %q is failed, col %q is not found in %s", name, rel.childCol(), childTy)
+		}
+		key, ok, err := getRelKey(val)
+		if err != nil {
+			return fmt.Errorf("preload field %q is failed, err: %v", name, err)
+		}
+		if ok {
+			key2ChildrenMap[key] = append(key2ChildrenMap[key], child)
+		}
+	}
+
+	// 回填
+	for _, parent := range parents {
+		val, _ := t.getFieldValueByCol(parent, rel.parentCol())
+		key, ok, _ := getRelKey(val) // 收集时已校验
+		if !ok {
+			continue
+		}
+		matched := key2ChildrenMap[key]
+		if len(matched) == 0 {
+			continue
+		}
+
+		fieldValue := parent.FieldByIndex(field.Index)
+		if rel.kind == internal.RelHasMany {
+			slice := reflect.MakeSlice(fieldValue.Type(), 0, len(matched))
+			isPtr := fieldValue.Type().Elem().Kind() == reflect.Ptr
+			for _, child := range matched {
+				if isPtr {
+					slice = reflect.Append(slice, child)
+				} else {
+					slice = reflect.Append(slice, child.Elem())
+				}
+			}
+			fieldValue.Set(slice)
+			continue
+		}
+		if fieldValue.Kind() == reflect.Ptr {
+			fieldValue.Set(matched[0])
+		} else {
+			fieldValue.Set(matched[0].Elem())
+		}
+	}
+	return nil
+}
+
+// newRelTable 创建查询关联表的 Table, 与当前 Table 使用同一个 db/ctx, 并沿用软删除的设置
+func (t *Table) newRelTable() *Table {
+	var child *Table
+	if t.engine != nil {
+		child = t.engine.NewTable()
+	} else {
+		child = NewTable(t.db).DbType(t.dbType)
+	}
+	child.db = t.db
+	child.tag = t.tag
+	child.isPrintSql = t.isPrintSql
+	child.softDelete = t.softDelete
+	child.softDeleteCol = t.softDeleteCol
+	child.unscoped = t.unscoped
+	return child.Ctx(t.ctx)
+}
+
+// getRelKey 获取关联值用于匹配, 会去掉指针并解析 driver.Valuer(如: sql.NullInt64), 为 nil 或零值时返回 false
+// 整型统一转为 int64, 防止本表和子表的类型不一致; 关联值需要作为 map 的 key, 不支持 slice/map/func 等类型
+func getRelKey(val reflect.Value) (any, bool, error) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil, false, nil
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return nil, false, nil
+	}
+	valuer, ok := val.Interface().(driver.Valuer)
+	if !ok && val.CanAddr() {
+		valuer, ok = val.Addr().Interface().(driver.Valuer)
+	}
+	if ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, false, err
+		}
+		if v == nil {
+			return nil, false, nil
+		}
+		val = reflect.ValueOf(v)
+	}
+	if val.IsZero() {
+		return nil, false, nil
+	}
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return val.Int(), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(val.Uint()), true, nil
+	case reflect.Slice:
+		if val.Type().Elem().Kind() == reflect.Uint8 { // []byte 不能作为 map 的 key
+			return string(val.Bytes()), true, nil
+		}
+	}
+	if !val.Type().Comparable() {
+		return nil, false, fmt.Errorf("rel key type %s is not support", val.Type())
+	}
+	return val.Interface(), true, nil
+}
@@ -0,0 +1,125 @@
+package spellsql
+
+import (
+	"database/sql"
+	"reflect"
+
+	"gitee.com/xuesongtao/spellsql/v2/internal"
+	"gitee.com/xuesongtao/spellsql/v2/utils"
+)
+
+// Rows 流式读取查询结果, 不会将结果集全部加载到内存中, 通过 Table.Rows 创建, 用法同 sql.Rows, 如:
+//
+//	rows, err := NewTable(db).SelectAuto(User{}).Rows()
+//	if err != nil {
+//		return err
+//	}
+//	defer rows.Close()
+//	for rows.Next() {
+//		var u User
+//		if err := rows.Scan(&u); err != nil {
+//			return err
+//		}
+//	}
+//	return rows.Err()
+type Rows struct {
+	t    *Table
+	rows *sql.Rows
+	plan *scanPlan
+	err  error
+}
+
+// Rows 流式查询, 每次调用 Next 前会检查 ctx 是否已取消
+// 注: 需要调用 Close, 防止连接泄露; 不支持 Preload
+func (t *Table) Rows() (*Rows, error) {
+	t.printSqlCallSkip++
+	rows, err := t.Query()
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{t: t, rows: rows}, nil
+}
+
+// Next 准备下一行, 没有数据或 ctx 取消时返回 false
+func (r *Rows) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	if err := r.t.ctx.Err(); err != nil {
+		r.err = err
+		_ = r.rows.Close()
+		return false
+	}
+	return r.rows.Next()
+}
+
+// Scan 将当前行映射到 dest, dest 支持 struct/单字段/map 的指针
+// 同一个 Rows 中 dest 类型不变时, 会复用字段的解析结果
+func (r *Rows) Scan(dest any) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if r.plan == nil || r.plan.destType != reflect.TypeOf(dest) {
+		ty, err := r.t.getDestReflectType(dest, []reflect.Kind{reflect.Struct, reflect.Map}, internal.FindOneDestTypeErr)
+		if err != nil && !utils.IsOneField(ty.Kind()) { // 需要排除单字段查询
+			return err
+		}
+		plan, err := r.t.newScanPlan(r.rows, ty)
+		if err != nil {
+			return err
+		}
+		plan.destType = reflect.TypeOf(dest)
+		r.plan = plan
+	}
+
+	base, err := r.t.scanRow(r.rows, r.plan)
+	if err != nil {
+		return err
+	}
+	destReflectValue := utils.RemoveValuePtr(reflect.ValueOf(dest))
+	if destReflectValue.Kind() == reflect.Ptr {
+		destReflectValue.Set(base.Addr())
+	} else {
+		destReflectValue.Set(base)
+	}
+	return nil
+}
+
+// Err 返回遍历过程中的错误, 包括 ctx 取消
+func (r *Rows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.rows.Err()
+}
+
+// Close 关闭结果集, 可以重复调用
+func (r *Rows) Close() error {
+	return r.rows.Close()
+}
+
+// RowsSeq 将 Rows 转为迭代器, 遍历结束后会关闭 rows, T 支持 struct/单字段/map 及其指针
+// 如(go1.23+):
+//
+//	for u, err := range RowsSeq[User](rows) {}
+func RowsSeq[T any](rows *Rows) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		defer rows.Close()
+
+		for rows.Next() {
+			var val T
+			if err := rows.Scan(&val); err != nil {
+				yield(val, err)
+				return
+			}
+			if !yield(val, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
@@ -109,7 +109,7 @@ func (t *Table) SelectAuto(src any, tableName ...string) *Table {
 		if utils.IsOneField(kind) { // 因为单字段不能解析查内容, 所以直接返回, 在最终调用处报错
 			return t
 		}
-		sLog.Warning(t.ctx, "src kind is not struct or slice struct")
+		t.getLogger().Warning(t.ctx, "src kind is not struct or slice struct")
 		t.SelectAll()
 	}
 	return t
@@ -312,6 +312,9 @@ func (t *Table) Count(total any) error {
 	if err := t.prevCheck(); err != nil {
 		return err
 	}
+	if err := t.applySoftDeleteFilter(); err != nil {
+		return err
+	}
 
 	// 这里不要释放, 如果是列表查询的话, 还会再进行查询内容操作
 	// defer t.free()
@@ -468,6 +471,9 @@ func (t *Table) Query() (*sql.Rows, error) {
 	if err := t.prevCheck(); err != nil {
 		return nil, err
 	}
+	if err := t.applySoftDeleteFilter(); err != nil {
+		return nil, err
+	}
 	_ = t.initCacheCol2InfoMap() // 为 getScanValues 解析 NULL 值做准备, 由于调用 Raw 时, 可能会出现没有表名, 所有需要忽略错误
 	after := &AfterHook{
 		St:       time.Now(),
@@ -533,6 +539,14 @@ func (t *Table) find(dest any, ty reflect.Type, ignoreRes bool, fn ...SelectCall
 	if err != nil {
 		return err
 	}
+	if err := t.scanRows(rows, dest, ty, ignoreRes, fn...); err != nil {
+		return err
+	}
+	return t.preload(dest) // 需要在 rows 关闭后再查询, 防止事务中连接被占用
+}
+
+// scanRows 根据 dest 类型处理结果集, 处理完后会关闭 rows
+func (t *Table) scanRows(rows *sql.Rows, dest any, ty reflect.Type, ignoreRes bool, fn ...SelectCallBackFn) error {
 	defer rows.Close()
 
 	t.loadDestType(ty)
@@ -545,6 +559,63 @@ func (t *Table) find(dest any, ty reflect.Type, ignoreRes bool, fn ...SelectCall
 	}
 }
 
+// scanPlan 结果集映射到 dest 的方式, 同一个结果集的每行可以复用
+type scanPlan struct {
+	destType                reflect.Type // 未去指针的类型, 用于 Rows.Scan 判断是否可以复用
+	ty                      reflect.Type // 去指针后的类型
+	destTypeFlag            uint8
+	colTypes                []*sql.ColumnType
+	col2StructFieldMap      map[string]structField
+	fieldIndex2NullIndexMap map[int]int // 用于记录 NULL 值到 struct 的映射关系
+	values                  []any
+	needAfterFind           bool
+}
+
+// newScanPlan 根据结果集的列和 ty 生成 scanPlan
+func (t *Table) newScanPlan(rows *sql.Rows, ty reflect.Type) (*scanPlan, error) {
+	t.loadDestType(ty)
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	colLen := len(colTypes)
+	col2StructFieldMap, _ := t.parseCol2StructField(ty, false)
+	return &scanPlan{
+		ty:                      ty,
+		destTypeFlag:            t.destTypeFlag,
+		colTypes:                colTypes,
+		col2StructFieldMap:      t.appendNestedStructField(ty, col2StructFieldMap, colTypes),
+		fieldIndex2NullIndexMap: make(map[int]int, colLen),
+		values:                  make([]any, colLen),
+		needAfterFind:           t.needAfterFind(ty),
+	}, nil
+}
+
+// scanRow 将当前行 Scan 到新建的 plan.ty 中
+func (t *Table) scanRow(rows *sql.Rows, plan *scanPlan) (reflect.Value, error) {
+	t.destTypeFlag = plan.destTypeFlag
+	base := reflect.New(plan.ty).Elem()
+	if err := t.getScanValues(base, plan.col2StructFieldMap, plan.fieldIndex2NullIndexMap, plan.colTypes, plan.values); err != nil {
+		return base, err
+	}
+
+	if err := rows.Scan(plan.values...); err != nil {
+		return base, fmt.Errorf("rows scan is failed, err: %v", err)
+	}
+
+	if err := t.setNullDest(base, plan.col2StructFieldMap, plan.fieldIndex2NullIndexMap, plan.colTypes, plan.values); err != nil {
+		return base, err
+	}
+
+	if plan.needAfterFind {
+		if err := t.callAfterFind(base); err != nil {
+			return base, err
+		}
+	}
+	return base, nil
+}
+
 // scanAll 处理多个结果集
 func (t *Table) scanAll(rows *sql.Rows, ty reflect.Type, dest any, fn ...SelectCallBackFn) error {
 	isPtr := ty.Kind() == reflect.Ptr
@@ -552,31 +623,18 @@ func (t *Table) scanAll(rows *sql.Rows, ty reflect.Type, dest any, fn ...SelectC
 		ty = utils.RemoveTypePtr(ty) // 去指针
 	}
 
-	t.loadDestType(ty)
-	colTypes, err := rows.ColumnTypes()
+	plan, err := t.newScanPlan(rows, ty)
 	if err != nil {
 		return err
 	}
 
-	colLen := len(colTypes)
-	col2StructFieldMap, _ := t.parseCol2StructField(ty, false)
-	fieldIndex2NullIndexMap := make(map[int]int, colLen) // 用于记录 NULL 值到 struct 的映射关系
-	values := make([]any, colLen)
 	destReflectValue := utils.RemoveValuePtr(reflect.ValueOf(dest))
 	if destReflectValue.IsNil() {
-		destReflectValue.Set(reflect.MakeSlice(destReflectValue.Type(), 0, colLen))
+		destReflectValue.Set(reflect.MakeSlice(destReflectValue.Type(), 0, len(plan.colTypes)))
 	}
 	for rows.Next() {
-		base := reflect.New(ty).Elem()
-		if err := t.getScanValues(base, col2StructFieldMap, fieldIndex2NullIndexMap, colTypes, values); err != nil {
-			return err
-		}
-
-		if err := rows.Scan(values...); err != nil {
-			return fmt.Errorf("rows scan is failed, err: %v", err)
-		}
-
-		if err := t.setNullDest(base, col2StructFieldMap, fieldIndex2NullIndexMap, colTypes, values); err != nil {
+		base, err := t.scanRow(rows, plan)
+		if err != nil {
 			return err
 		}
 
@@ -603,30 +661,17 @@ func (t *Table) scanAll(rows *sql.Rows, ty reflect.Type, dest any, fn ...SelectC
 
 // scanOne 处理单个结果集
 func (t *Table) scanOne(rows *sql.Rows, ty reflect.Type, dest any, ignoreRes bool, fn ...SelectCallBackFn) error {
-	// t.loadDestType(ty) // 这里可以不用再处理
-	colTypes, err := rows.ColumnTypes()
+	plan, err := t.newScanPlan(rows, ty)
 	if err != nil {
 		return err
 	}
 
-	colLen := len(colTypes)
-	col2StructFieldMap, _ := t.parseCol2StructField(ty, false)
-	values := make([]any, colLen)
-	fieldIndex2NullIndexMap := make(map[int]int, colLen) // 用于记录 NULL 值到 struct 的映射关系
 	destReflectValue := utils.RemoveValuePtr(reflect.ValueOf(dest))
 	haveNoData := true
 	for rows.Next() {
 		haveNoData = false
-		base := reflect.New(ty).Elem()
-		if err := t.getScanValues(base, col2StructFieldMap, fieldIndex2NullIndexMap, colTypes, values); err != nil {
-			return err
-		}
-
-		if err := rows.Scan(values...); err != nil {
-			return err
-		}
-
-		if err := t.setNullDest(base, col2StructFieldMap, fieldIndex2NullIndexMap, colTypes, values); err != nil {
+		base, err := t.scanRow(rows, plan)
+		if err != nil {
 			return err
 		}
 
@@ -658,6 +703,91 @@ func (t *Table) scanOne(rows *sql.Rows, ty reflect.Type, dest any, ignoreRes boo
 	return nil
 }
 
+// appendNestedStructField 将带前缀的列映射到嵌套结构体的字段, 用于连表查询, 如:
+//
+//	type UserOrder struct {
+//		User  *User `json:"u"`
+//		Order Order `json:"o"`
+//	}
+//	SELECT u.name AS u__name, o.id AS "o.id" FROM user u LEFT JOIN order o ON ...
+//
+// 前缀为嵌套结构体字段的 tag, 分隔符支持 . 和 __, 嵌套结构体为指针且对应的列都为 NULL 时, 该字段为 nil
+// 注: 返回的是新的 map, 不会修改缓存中的 col2StructFieldMap
+func (t *Table) appendNestedStructField(ty reflect.Type, col2StructFieldMap map[string]structField, colTypes []*sql.ColumnType) map[string]structField {
+	if col2StructFieldMap == nil {
+		return col2StructFieldMap
+	}
+
+	var res map[string]structField
+	for _, colType := range colTypes {
+		col := colType.Name()
+		if _, ok := col2StructFieldMap[col]; ok {
+			continue
+		}
+		field, ok := t.getNestedStructField(ty, col)
+		if !ok {
+			continue
+		}
+		if res == nil {
+			res = make(map[string]structField, len(col2StructFieldMap)+len(colTypes))
+			for k, v := range col2StructFieldMap {
+				res[k] = v
+			}
+		}
+		res[col] = field
+	}
+	if res == nil {
+		return col2StructFieldMap
+	}
+	return res
+}
+
+// getNestedStructField 通过列名前缀获取嵌套结构体的字段, 支持多层, 如: u__addr__city
+func (t *Table) getNestedStructField(ty reflect.Type, col string) (structField, bool) {
+	for _, sep := range []string{".", "__"} {
+		i := strings.Index(col, sep)
+		if i <= 0 {
+			continue
+		}
+		prefix, subCol := col[:i], col[i+len(sep):]
+		col2StructFieldMap, _ := t.parseCol2StructField(ty, false)
+		parent, ok := col2StructFieldMap[prefix]
+		if !ok {
+			continue
+		}
+		nestedTy := utils.RemoveTypePtr(ty.FieldByIndex(parent.index).Type)
+		if nestedTy.Kind() != reflect.Struct {
+			continue
+		}
+
+		nestedCol2StructFieldMap, _ := t.parseCol2StructField(nestedTy, false)
+		child, ok := nestedCol2StructFieldMap[subCol]
+		if !ok {
+			if child, ok = t.getNestedStructField(nestedTy, subCol); !ok {
+				continue
+			}
+		}
+		index := make([]int, 0, len(parent.index)+len(child.index))
+		index = append(index, parent.index...)
+		index = append(index, child.index...)
+		return structField{index: index, tagName: child.tagName, nested: true}, true
+	}
+	return structField{}, false
+}
+
+// isNullScanVal nullScan 的 src 是否为 NULL
+func isNullScanVal(src any) bool {
+	switch val := src.(type) {
+	case *sql.NullString:
+		return !val.Valid
+	case *sql.NullInt64:
+		return !val.Valid
+	case *sql.NullFloat64:
+		return !val.Valid
+	}
+	return false
+}
+
 // isDestType
 func (t *Table) isDestType(typeNum uint8) bool {
 	return internal.Equal(t.destTypeFlag, typeNum)
@@ -668,16 +798,18 @@ func (t *Table) getScanValues(dest reflect.Value, col2StructFieldMap map[string]
 	var structMissFields []string
 	for i, colType := range colTypes {
 		var (
-			fieldIndex       int
+			fieldIndex       []int
 			tagName          string
+			nested           bool
 			colName          = colType.Name()
 			structFieldExist = true
 		)
 		if t.isDestType(structFlag) && col2StructFieldMap != nil {
 			var tmp structField
 			tmp, structFieldExist = col2StructFieldMap[colName]
-			fieldIndex = tmp.offsetIndex
+			fieldIndex = tmp.index
 			tagName = tmp.tagName
+			nested = tmp.nested
 		}
 
 		// 说明结构里查询的值不存在
@@ -691,7 +823,9 @@ func (t *Table) getScanValues(dest reflect.Value, col2StructFieldMap map[string]
 
 		// NULL 值处理, 防止 sql 报错, 否则就直接 Scan 到输入的 dest addr
 		mayIsNull, _ := colType.Nullable() // 根据此获取的 NULL 值不准确(不同的 drive 返回不同), 但如果为 true 的话就没有问题
-		if !mayIsNull {                    // 防止误判, 再判断下
+		if nested {                        // 嵌套结构体的列需要判断是否为 NULL, 用于保持指针为 nil
+			mayIsNull = true
+		} else if !mayIsNull { // 防止误判, 再判断下
 			colInfo := t.cacheCol2InfoMap[colName]
 
 			// 当 colInfo == nil 就直接通过 NULL 值处理, 如以下情况:
@@ -711,10 +845,10 @@ func (t *Table) getScanValues(dest reflect.Value, col2StructFieldMap map[string]
 				values[i] = cacheNullString.Get().(*sql.NullString)
 			}
 
-			// struct, 这里记录 struct 那个字段需要映射 NULL 值
+			// struct, 这里记录第几列需要映射 NULL 值, 在 setNullDest 中通过列名找到对应的字段
 			// map/单字段, 为了减少创建标记, 借助 fieldIndex2NullIndexMap 用于标识单字段是否包含空值,  在 setNullDest 使用
 			if t.isDestType(structFlag) && structFieldExist {
-				fieldIndex2NullIndexMap[fieldIndex] = i
+				fieldIndex2NullIndexMap[i] = i
 			} else if t.isDestType(mapFlag) || t.isDestType(sliceFlag) || t.isDestType(oneFieldFlag) {
 				fieldIndex2NullIndexMap[i] = i
 			}
@@ -726,10 +860,10 @@ func (t *Table) getScanValues(dest reflect.Value, col2StructFieldMap map[string]
 			// 在非 NULL 的时候, 也判断下是否需要反序列化
 			if handleStructField, ok := t.waitHandleStructFieldMap[tagName]; ok && handleStructField.unmarshal != nil {
 				values[i] = cacheNullString.Get().(*sql.NullString)
-				fieldIndex2NullIndexMap[fieldIndex] = i
+				fieldIndex2NullIndexMap[i] = i
 				continue
 			}
-			values[i] = dest.Field(fieldIndex).Addr().Interface()
+			values[i] = utils.FieldByIndexAlloc(dest, fieldIndex).Addr().Interface()
 		} else if t.isDestType(mapFlag) {
 			destValType := dest.Type().Elem()
 			if destValType.Kind() == reflect.Interface {
@@ -787,11 +921,13 @@ func (t *Table) nullScan(dest, src any, needUnmarshalField ...string) (err error
 // setNullDest 设置值
 func (t *Table) setNullDest(dest reflect.Value, col2StructFieldMap map[string]structField, fieldIndex2NullIndexMap map[int]int, colTypes []*sql.ColumnType, scanResult []any) error {
 	if t.isDestType(structFlag) {
-		for fieldIndex, nullIndex := range fieldIndex2NullIndexMap {
-			col := colTypes[nullIndex].Name()
-			tag := col2StructFieldMap[col].tagName
-			destFieldValue := dest.Field(fieldIndex)
-			if err := t.nullScan(destFieldValue.Addr().Interface(), scanResult[nullIndex], tag); err != nil {
+		for _, nullIndex := range fieldIndex2NullIndexMap {
+			field := col2StructFieldMap[colTypes[nullIndex].Name()]
+			if field.nested && isNullScanVal(scanResult[nullIndex]) { // 不赋值, 防止初始化嵌套结构体指针
+				continue
+			}
+			destFieldValue := utils.FieldByIndexAlloc(dest, field.index)
+			if err := t.nullScan(destFieldValue.Addr().Interface(), scanResult[nullIndex], field.tagName); err != nil {
 				return err
 			}
 		}
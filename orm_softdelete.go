@@ -0,0 +1,145 @@
+package spellsql
+
+import (
+	"fmt"
+	"strings"
+
+	"gitee.com/xuesongtao/spellsql/v2/builder"
+	"gitee.com/xuesongtao/spellsql/v2/dialect"
+	"gitee.com/xuesongtao/spellsql/v2/internal"
+)
+
+// SoftDelete 开启软删除, col 为软删除的列名, 默认按 deleted_at, is_deleted 的顺序从表字段中检测
+// 开启后:
+//
+//	1.Delete/DeleteWhere 会转为 UPDATE, 如: UPDATE xxx SET deleted_at = now WHERE xxx AND deleted_at IS NULL
+//	2.通过 Table 构建的查询(Select/SelectAuto/FindWhere/Count 等)会自动添加 deleted_at IS NULL 条件
+//
+// 列类型为整型/布尔时, 删除值为 1, 未删除值为 0; 其他类型(如: 时间)删除值为当前时间(见 NowFn), 未删除值为 NULL
+// 注: Raw 传入的 sql 字符串不会处理, 如果需要忽略软删除可以调用 Unscoped
+func (t *Table) SoftDelete(col ...string) *Table {
+	t.softDelete = true
+	if len(col) > 0 {
+		t.softDeleteCol = col[0]
+	}
+	return t
+}
+
+// Unscoped 忽略软删除, Delete 为物理删除, 查询也不会添加软删除条件
+func (t *Table) Unscoped() *Table {
+	t.unscoped = true
+	return t
+}
+
+// getSoftDeleteColInfo 获取软删除列, 没有开启或表中没有默认的软删除列时返回 nil
+func (t *Table) getSoftDeleteColInfo() (*dialect.TableColInfo, error) {
+	if !t.softDelete || t.unscoped {
+		return nil, nil
+	}
+	if err := t.initCacheCol2InfoMap(); err != nil {
+		return nil, err
+	}
+
+	if t.softDeleteCol != "" {
+		info, ok := t.cacheCol2InfoMap[t.softDeleteCol]
+		if !ok {
+			return nil, fmt.Errorf("soft delete col %q is not exist in table %s", t.softDeleteCol, t.name)
+		}
+		return info, nil
+	}
+	for _, col := range []string{internal.SoftDeleteAtCol, internal.SoftDeleteFlagCol} {
+		if info, ok := t.cacheCol2InfoMap[col]; ok {
+			return info, nil
+		}
+	}
+	return nil, nil
+}
+
+// isSoftDeleteFlag 软删除列是否为标记类型(整型/布尔)
+func (t *Table) isSoftDeleteFlag(info *dialect.TableColInfo) bool {
+	colType := strings.ToLower(info.Type)
+	return strings.Contains(colType, "int") || strings.Contains(colType, "bool") || strings.Contains(colType, "bit")
+}
+
+// getSoftDeleteWhere 获取未删除的条件
+// qualifier 为列的限定名(表名或别名), 用于连表查询
+func (t *Table) getSoftDeleteWhere(info *dialect.TableColInfo, qualifier string) (string, []any, error) {
+	gd, err := dialect.GetDialect(t.dbType)
+	if err != nil {
+		return "", nil, err
+	}
+	col := dialect.WarpCol(gd, info.Field)
+	if qualifier != "" {
+		col = qualifier + "." + col
+	}
+	if t.isSoftDeleteFlag(info) {
+		return col + " = ?", []any{0}, nil
+	}
+	return col + " IS NULL", nil, nil
+}
+
+// applySoftDeleteFilter 对通过 Table 构建的查询添加未删除的条件, 只会添加一次
+func (t *Table) applySoftDeleteFilter() error {
+	if t.softDeleteApplied {
+		return nil
+	}
+
+	selectBuilder, ok := t.builder.(*builder.Select)
+	if !ok || selectBuilder.GetTableName() == "" {
+		return nil
+	}
+	info, err := t.getSoftDeleteColInfo()
+	if info == nil {
+		return err
+	}
+
+	// 使用别名或表名限定列, 防止连表时列名冲突, 如: man m => m.deleted_at
+	tableFields := strings.Fields(selectBuilder.GetTableName())
+	sqlStr, args, err := t.getSoftDeleteWhere(info, tableFields[len(tableFields)-1])
+	if err != nil {
+		return err
+	}
+	t.softDeleteApplied = true
+
+	// 原有条件需要作为一个整体, 防止 OR 条件导致过滤失效
+	where := builder.NewWhere(t.dbType)
+	if oldWhere := selectBuilder.Where(); oldWhere != nil && !oldWhere.Empty() {
+		where.AndGroup(oldWhere)
+	}
+	where.And(sqlStr, args...)
+	selectBuilder.SetWhere(where)
+	return nil
+}
+
+// convSoftDeleteBuilder 将通过 Table 构建的 Delete 转为 Update
+func (t *Table) convSoftDeleteBuilder() error {
+	deleteBuilder, ok := t.builder.(*builder.Delete)
+	if !ok || deleteBuilder.GetTableName() == "" {
+		return nil
+	}
+	info, err := t.getSoftDeleteColInfo()
+	if info == nil {
+		return err
+	}
+
+	var deletedVal any = t.now()
+	if t.isSoftDeleteFlag(info) {
+		deletedVal = 1
+	}
+
+	// 已经删除的不需要再更新
+	sqlStr, args, err := t.getSoftDeleteWhere(info, "")
+	if err != nil {
+		return err
+	}
+	where := builder.NewWhere(t.dbType)
+	if oldWhere := deleteBuilder.Where(); oldWhere != nil && !oldWhere.Empty() {
+		where.AndGroup(oldWhere)
+	}
+	where.And(sqlStr, args...)
+	t.builder = builder.NewUpdate(t.dbType).
+		Table(deleteBuilder.GetTableName()).
+		Set(info.Field, deletedVal).
+		SetWhere(where)
+	return nil
+}
@@ -1,15 +1,22 @@
 package spellsql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"math"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"gitee.com/xuesongtao/spellsql/v2/builder"
 	"gitee.com/xuesongtao/spellsql/v2/internal"
@@ -80,6 +87,35 @@ const (
 //   PRIMARY KEY (`id`)
 // );
 
+// CREATE TABLE `soft_man` (
+//   `id` int NOT NULL AUTO_INCREMENT,
+//   `name` varchar(10) NOT NULL,
+//   `deleted_at` datetime DEFAULT NULL,
+//   PRIMARY KEY (`id`)
+// );
+
+// CREATE TABLE `ts_man` (
+//   `id` int NOT NULL AUTO_INCREMENT,
+//   `name` varchar(10) NOT NULL,
+//   `created_at` datetime DEFAULT NULL,
+//   `updated_at` datetime DEFAULT NULL,
+//   PRIMARY KEY (`id`)
+// );
+
+// CREATE TABLE `ver_man` (
+//   `id` int NOT NULL AUTO_INCREMENT,
+//   `name` varchar(10) NOT NULL,
+//   `version` int NOT NULL DEFAULT '0',
+//   PRIMARY KEY (`id`)
+// );
+
+// CREATE TABLE `man_order` (
+//   `id` int NOT NULL AUTO_INCREMENT,
+//   `man_id` int NOT NULL,
+//   `sku` varchar(20) NOT NULL,
+//   PRIMARY KEY (`id`)
+// );
+
 type ManCopy struct {
 	Id       int32  `json:"id,omitempty" gorm:"id" db:"id"`
 	Name     string `json:"name,omitempty" gorm:"name" db:"name"`
@@ -89,6 +125,17 @@ type ManCopy struct {
 	// ManSons  []ManSon `json:"mansons,omitempty" gorm:"mansons" db:"mansons"`
 }
 
+type BaseModel struct {
+	Id   int32  `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type ManEmbedded struct {
+	*BaseModel
+	Age  int32  `json:"age,omitempty"`
+	Addr string `json:"addr,omitempty"`
+}
+
 type ManSon struct {
 	Like string `json:"like,omitempty" gorm:"like" db:"like"`
 	Desc string `json:"desc,omitempty" gorm:"desc" db:"desc"`
@@ -653,6 +700,31 @@ func TestInsert(t *testing.T) {
 			t.Error("insert is failed")
 		}
 	})
+
+	t.Run("insert backfill id", func(t *testing.T) {
+		m1 := &test.Man{Name: sureName, Age: sureAge}
+		res, err := NewTable(db, "man").Insert(m1).Exec()
+		if err != nil {
+			t.Fatal(err)
+		}
+		lastId, _ := res.LastInsertId()
+		if m1.Id == 0 || !test.Equal(int64(m1.Id), lastId) {
+			t.Error(test.NoEqErr)
+		}
+	})
+
+	t.Run("batch insert backfill id", func(t *testing.T) {
+		m1 := &test.Man{Name: sureName, Age: sureAge}
+		m2 := &test.Man{Name: sureName, Age: sureAge}
+		res, err := NewTable(db, "man").AutoIncLockMode(AutoIncLockModeConsecutive).Insert(m1, m2).Exec()
+		if err != nil {
+			t.Fatal(err)
+		}
+		lastId, _ := res.LastInsertId()
+		if !test.Equal(int64(m1.Id), lastId) || !test.Equal(m2.Id, m1.Id+1) {
+			t.Error(test.NoEqErr)
+		}
+	})
 }
 
 func TestDelete(t *testing.T) {
@@ -674,6 +746,725 @@ func TestDelete(t *testing.T) {
 	})
 }
 
+func TestAutoTimestamp(t *testing.T) {
+	type TsMan struct {
+		Id        int32  `json:"id"`
+		Name      string `json:"name"`
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	m := &TsMan{Name: sureName}
+	_, err := NewTable(db, "ts_man").AutoTimestamp().NowFn(func() time.Time { return createdAt }).Insert(m).Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updatedAt := createdAt.Add(time.Hour)
+	_, err = NewTable(db, "ts_man").AutoTimestamp().NowFn(func() time.Time { return updatedAt }).Update(&TsMan{Name: "test"}, "id=?", m.Id).Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res TsMan
+	if err := NewTable(db, "ts_man").FindWhere(&res, "id=?", m.Id); err != nil {
+		t.Fatal(err)
+	}
+	if !test.Equal(res.CreatedAt, createdAt.Format(internal.TimeFmt)) || !test.Equal(res.UpdatedAt, updatedAt.Format(internal.TimeFmt)) {
+		t.Error(test.NoEqErr, res)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	type VerMan struct {
+		Id      int32  `json:"id"`
+		Name    string `json:"name"`
+		Version int    `json:"version"`
+	}
+
+	m := &VerMan{Name: sureName}
+	if _, err := NewTable(db, "ver_man").Insert(m).Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟并发, 两个对象持有同一个版本号
+	m1, m2 := *m, *m
+	m1.Name = "test1"
+	if _, err := NewTable(db, "ver_man").Version().Update(&m1, "id=?", m.Id).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if !test.Equal(m1.Version, m.Version+1) {
+		t.Error(test.NoEqErr)
+	}
+
+	m2.Name = "test2"
+	_, err := NewTable(db, "ver_man").Version().Update(&m2, "id=?", m.Id).Exec()
+	if !IsStaleObject(err) {
+		t.Error("it should is stale, err:", err)
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	m := &ManEmbedded{BaseModel: &BaseModel{Name: sureName}, Age: sureAge, Addr: sureAddr}
+	if _, err := NewTable(db, "man").Insert(m).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Id == 0 {
+		t.Error("id should backfill")
+	}
+
+	var res ManEmbedded
+	if err := NewTable(db, "man").SelectAuto(res).FindWhere(&res, "id=?", m.Id); err != nil {
+		t.Fatal(err)
+	}
+	if res.BaseModel == nil || !test.Equal(res.Name, sureName) || !test.Equal(res.Age, sureAge) {
+		t.Error(test.NoEqErr)
+	}
+
+	var all []*ManEmbedded
+	if err := NewTable(db, "man").SelectAuto(ManEmbedded{}).Where("id=?", m.Id).FindAll(&all); err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || !test.Equal(all[0].Id, m.Id) {
+		t.Error(test.NoEqErr)
+	}
+}
+
+func TestNestedScan(t *testing.T) {
+	type ManJoin struct {
+		Id     int32     `json:"id"`
+		Man    *ManCopy  `json:"m"`
+		Parent *ManCopy  `json:"p"`
+		Son    BaseModel `json:"s"`
+	}
+
+	var res []*ManJoin
+	err := NewTable(db).Raw("SELECT m.id, m.name AS `m.name`, m.age AS m__age, p.name AS p__name, m.name AS s__name FROM man m LEFT JOIN man p ON p.id = -1 WHERE m.id > 0 LIMIT 1").FindAll(&res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) == 0 {
+		t.Fatal("res is empty")
+	}
+	if res[0].Man == nil || !test.Equal(res[0].Man.Name, res[0].Son.Name) {
+		t.Error(test.NoEqErr)
+	}
+	if res[0].Parent != nil {
+		t.Error("left join is null, parent should is nil")
+	}
+}
+
+type RelMan struct {
+	Id     int32       `json:"id"`
+	Name   string      `json:"name"`
+	Orders []*ManOrder `json:"orders" rel:"has_many,foreign=man_id"`
+}
+
+func (RelMan) TableName() string {
+	return "man"
+}
+
+type ManOrder struct {
+	Id    int32   `json:"id"`
+	ManId int32   `json:"man_id"`
+	Sku   string  `json:"sku"`
+	Man   *RelMan `json:"man" rel:"belongs_to,foreign=man_id"`
+}
+
+func TestPreload(t *testing.T) {
+	m := &RelMan{Name: sureName}
+	if _, err := NewTable(db).Insert(m).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	orders := []any{&ManOrder{ManId: m.Id, Sku: "sku1"}, &ManOrder{ManId: m.Id, Sku: "sku2"}}
+	if _, err := NewTable(db).Insert(orders...).Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("has many", func(t *testing.T) {
+		var res []*RelMan
+		err := NewTable(db).SelectAuto(RelMan{}).Where("id=?", m.Id).Preload("Orders", "Orders.Man").FindAll(&res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 1 || len(res[0].Orders) != 2 {
+			t.Fatal(test.NoEqErr)
+		}
+		if res[0].Orders[0].Man == nil || !test.Equal(res[0].Orders[0].Man.Id, m.Id) {
+			t.Error(test.NoEqErr)
+		}
+	})
+
+	t.Run("belongs to", func(t *testing.T) {
+		var res ManOrder
+		err := NewTable(db).SelectAuto(res).Where("man_id=?", m.Id).Preload("Man").FindOne(&res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Man == nil || !test.Equal(res.Man.Name, sureName) {
+			t.Error(test.NoEqErr)
+		}
+	})
+}
+
+type NullManOrder struct {
+	Id    int32   `json:"id"`
+	ManId *int32  `json:"man_id"`
+	Man   *RelMan `json:"man" rel:"belongs_to,foreign=man_id"`
+}
+
+func (NullManOrder) TableName() string {
+	return "man_order"
+}
+
+func TestPreloadOfNullKey(t *testing.T) {
+	const orderNum = 1002 // 最后一个订单的 man_id 为 NULL
+	mdb, mockDb := newMockDb(func(query string, args []driver.Value) *mockResult {
+		isOrder := strings.Contains(query, "man_order")
+		if strings.HasPrefix(query, "SHOW COLUMNS") {
+			if isOrder {
+				return &mockResult{
+					cols: []string{"Field", "Type", "Null", "Key", "Default", "Extra"},
+					rows: [][]driver.Value{{"id", "int", "NO", "PRI", nil, "auto_increment"}, {"man_id", "int", "YES", "", nil, ""}},
+				}
+			}
+			res := mockManCols()
+			res.rows = append(res.rows, []driver.Value{"deleted_at", "datetime", "YES", "", nil, ""})
+			return res
+		}
+		if isOrder {
+			res := &mockResult{cols: []string{"id", "man_id"}}
+			for id := int64(1); id <= orderNum; id++ {
+				var manId driver.Value = id
+				if id == orderNum {
+					manId = nil
+				}
+				res.rows = append(res.rows, []driver.Value{id, manId})
+			}
+			return res
+		}
+		res := &mockResult{cols: []string{"id", "name"}}
+		for _, arg := range args {
+			res.rows = append(res.rows, []driver.Value{arg, sureName})
+		}
+		return res
+	})
+	defer mdb.Close()
+
+	var res []*NullManOrder
+	if err := NewTable(mdb).SoftDelete().SelectAuto(NullManOrder{}).Preload("Man").FindAll(&res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != orderNum {
+		t.Fatalf("res len got: %d, want: %d", len(res), orderNum)
+	}
+	for _, order := range res {
+		if order.ManId == nil {
+			if order.Man != nil {
+				t.Errorf("order %d man should is nil", order.Id)
+			}
+			continue
+		}
+		if order.Man == nil || order.Man.Id != *order.ManId {
+			t.Errorf("order %d man is not match", order.Id)
+		}
+	}
+
+	// 1001 个关联值, 每批 1000 个, 分 2 批查询
+	var manQueries []string
+	for _, query := range mockDb.Queries() {
+		if strings.HasPrefix(query, "SELECT") && !strings.Contains(query, "man_order") {
+			manQueries = append(manQueries, query)
+		}
+	}
+	if len(manQueries) != 2 {
+		t.Fatalf("man queries len got: %d, want: 2", len(manQueries))
+	}
+	for _, query := range manQueries {
+		if !strings.Contains(query, "`deleted_at` IS NULL") {
+			t.Errorf("man query should has soft delete cond, got: %s", query)
+		}
+	}
+}
+
+func TestGetRelKey(t *testing.T) {
+	var (
+		id    int32 = 1
+		nilId *int32
+	)
+	cases := []struct {
+		val  any
+		want any
+		ok   bool
+		err  bool
+	}{
+		{val: id, want: int64(1), ok: true},
+		{val: &id, want: int64(1), ok: true},
+		{val: nilId, ok: false},
+		{val: uint8(2), want: int64(2), ok: true},
+		{val: 0, ok: false},
+		{val: sql.NullInt64{Int64: 3, Valid: true}, want: int64(3), ok: true},
+		{val: sql.NullInt64{}, ok: false},
+		{val: sql.NullString{String: "a", Valid: true}, want: "a", ok: true},
+		{val: []byte("b"), want: "b", ok: true},
+		{val: []int32{1}, err: true},
+		{val: map[string]int{"a": 1}, err: true},
+		{val: func() {}, err: true},
+	}
+	for i, c := range cases {
+		got, ok, err := getRelKey(reflect.ValueOf(c.val))
+		if (err != nil) != c.err {
+			t.Errorf("case %d err got: %v, want err: %v", i, err, c.err)
+			continue
+		}
+		if ok != c.ok || got != c.want {
+			t.Errorf("case %d got: %v, %v, want: %v, %v", i, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+type SliceManOrder struct {
+	Id    int32   `json:"id"`
+	ManId []int32 `json:"man_id"`
+	Man   *RelMan `json:"man" rel:"belongs_to,foreign=man_id"`
+}
+
+func (SliceManOrder) TableName() string {
+	return "man_order"
+}
+
+func TestPreloadOfUnhashableKey(t *testing.T) {
+	mdb, _ := newMockDb(func(query string, args []driver.Value) *mockResult {
+		if strings.HasPrefix(query, "SHOW COLUMNS") {
+			return &mockResult{
+				cols: []string{"Field", "Type", "Null", "Key", "Default", "Extra"},
+				rows: [][]driver.Value{{"id", "int", "NO", "PRI", nil, "auto_increment"}, {"man_id", "json", "YES", "", nil, ""}},
+			}
+		}
+		return &mockResult{cols: []string{"id", "man_id"}, rows: [][]driver.Value{{int64(1), []byte("[1, 2]")}}}
+	})
+	defer mdb.Close()
+
+	var res []*SliceManOrder
+	err := NewTable(mdb).SelectAuto(SliceManOrder{}).Preload("Man").FindAll(&res)
+	if err == nil || !strings.Contains(err.Error(), "rel key type []int32 is not support") {
+		t.Errorf("unhashable rel key should be failed, err: %v", err)
+	}
+}
+
+func TestQueryGeneric(t *testing.T) {
+	ctx := context.Background()
+	m := &RelMan{Name: sureName}
+	if _, err := NewTable(db).Insert(m).Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("all", func(t *testing.T) {
+		res, err := Query[*RelMan](db).Where("id=?", m.Id).All(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 1 || !test.Equal(res[0].Name, sureName) {
+			t.Error(test.NoEqErr)
+		}
+	})
+
+	t.Run("one", func(t *testing.T) {
+		res, err := Query[RelMan](db).Where("id=?", m.Id).One(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !test.Equal(res.Id, m.Id) {
+			t.Error(test.NoEqErr)
+		}
+
+		_, err = Query[RelMan](db).Where("id=?", -1).One(ctx)
+		if !IsNullRow(err) {
+			t.Error("should is null row")
+		}
+	})
+
+	t.Run("iter", func(t *testing.T) {
+		count := 0
+		Query[RelMan](db).Limit(1, 10).Iter(ctx)(func(row RelMan, err error) bool {
+			if err != nil {
+				t.Fatal(err)
+			}
+			count++
+			return count < 2
+		})
+		if count > 2 {
+			t.Error("iter should stop")
+		}
+	})
+}
+
+func TestRows(t *testing.T) {
+	rows, err := NewTable(db).SelectAuto(RelMan{}).Limit(1, 10).Rows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var m RelMan
+		if err := rows.Scan(&m); err != nil {
+			t.Fatal(err)
+		}
+		if m.Id == 0 {
+			t.Error(test.NoEqErr)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("count:", count)
+
+	t.Run("ctx cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		rows, err := NewTable(db).Ctx(ctx).SelectAuto(RelMan{}).Limit(1, 10).Rows()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+
+		cancel()
+		if rows.Next() {
+			t.Error("ctx is canceled, should stop")
+		}
+		if !errors.Is(rows.Err(), context.Canceled) {
+			t.Error(test.NoEqErr)
+		}
+	})
+}
+
+func TestInsertBatch(t *testing.T) {
+	objs := make([]any, 0, 5)
+	for i := 0; i < 5; i++ {
+		objs = append(objs, &test.Man{Name: sureName, Age: int32(i + 1)})
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		res, err := NewTable(db).InsertBatch(objs, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !test.Equal(res.RowsAffected, int64(len(objs))) {
+			t.Error(test.NoEqErr)
+		}
+	})
+
+	t.Run("in tx", func(t *testing.T) {
+		res, err := NewTable(db).BatchInTx(true).InsertBatch(objs, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !test.Equal(res.RowsAffected, int64(len(objs))) {
+			t.Error(test.NoEqErr)
+		}
+	})
+}
+
+func TestUpdateBatch(t *testing.T) {
+	objs := []any{&test.Man{Name: sureName, Age: 1}, &test.Man{Name: sureName, Age: 2}}
+	if _, err := NewTable(db).Insert(objs...).Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	m1, m2 := objs[0].(*test.Man), objs[1].(*test.Man)
+	m1.Age, m2.Age = 10, 20
+	res, err := NewTable(db).UpdateBatch(objs, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !test.Equal(res.RowsAffected, int64(2)) {
+		t.Error(test.NoEqErr)
+	}
+
+	var got test.Man
+	if err := NewTable(db).SelectAuto(got).Where("id=?", m2.Id).FindOne(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !test.Equal(got.Age, m2.Age) {
+		t.Error(test.NoEqErr)
+	}
+}
+
+func TestBatchCallInfo(t *testing.T) {
+	mdb, _ := newMockDb(func(query string, args []driver.Value) *mockResult {
+		if strings.HasPrefix(query, "SHOW COLUMNS") {
+			return mockManCols()
+		}
+		return &mockResult{rowsAffected: 1}
+	})
+	defer mdb.Close()
+
+	for _, inTx := range []bool{false, true} {
+		var calls []string
+		table := NewTable(mdb, "man").BatchInTx(inTx).AfterHook(func(_ context.Context, ah *AfterHook) {
+			calls = append(calls, ah.GetCall())
+		})
+
+		objs := []any{&test.Man{Id: 1, Name: sureName}, &test.Man{Id: 2, Name: sureName}}
+		_, file, line, _ := runtime.Caller(0)
+		res, err := table.UpdateBatch(objs, "id", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := res.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		want := filepath.Base(file) + ":" + strconv.Itoa(line+1)
+		if len(calls) != len(objs) {
+			t.Fatalf("inTx: %v, calls len got: %d, want: %d", inTx, len(calls), len(objs))
+		}
+		for _, call := range calls {
+			if call != want {
+				t.Errorf("inTx: %v, call got: %s, want: %s", inTx, call, want)
+			}
+		}
+	}
+}
+
+func TestUpdateBatchOfDiffCols(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		maxArgs int
+	)
+	mdb, _ := newMockDb(func(query string, args []driver.Value) *mockResult {
+		if strings.HasPrefix(query, "SHOW COLUMNS") {
+			return mockManCols()
+		}
+		mu.Lock()
+		if len(args) > maxArgs {
+			maxArgs = len(args)
+		}
+		mu.Unlock()
+		return &mockResult{rowsAffected: 1}
+	})
+	defer mdb.Close()
+
+	t.Run("version", func(t *testing.T) {
+		_, err := NewTable(mdb).Version().UpdateBatch([]any{&test.Man{Id: 1, Name: sureName}}, "id")
+		if err == nil || !strings.Contains(err.Error(), "not support version") {
+			t.Errorf("update batch with version should be failed, err: %v", err)
+		}
+	})
+
+	t.Run("placeholder", func(t *testing.T) {
+		// 第一个对象只更新 name, 其他对象更新 name/age/addr, 每批的占位符数不能超过限制
+		objs := make([]any, 0, 12000)
+		objs = append(objs, &ManCopy{Id: 1, Name: sureName})
+		for i := 2; i <= cap(objs); i++ {
+			objs = append(objs, &ManCopy{Id: int32(i), Name: sureName, Age: sureAge, Addr: "addr"})
+		}
+		res, err := NewTable(mdb, "man").UpdateBatch(objs, "id", 20000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := res.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if maxArgs == 0 || maxArgs > internal.MaxPlaceholderNum {
+			t.Errorf("max args got: %d, it should lte %d", maxArgs, internal.MaxPlaceholderNum)
+		}
+	})
+}
+
+func TestUpsert(t *testing.T) {
+	m := &test.Man{Name: sureName, Age: 1}
+	if _, err := NewTable(db).Insert(m).Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("update", func(t *testing.T) {
+		m.Age = 2
+		if _, err := NewTable(db).Upsert([]any{m}, UpsertOpts{UpdateCols: []string{"age"}}).Exec(); err != nil {
+			t.Fatal(err)
+		}
+		var age int32
+		if err := NewTable(db, "man").Select("age").Where("id=?", m.Id).FindOne(&age); err != nil {
+			t.Fatal(err)
+		}
+		if !test.Equal(age, m.Age) {
+			t.Error(test.NoEqErr)
+		}
+	})
+
+	t.Run("do nothing", func(t *testing.T) {
+		m.Age = 3
+		if _, err := NewTable(db).Upsert([]any{m}, UpsertOpts{DoNothing: true}).Exec(); err != nil {
+			t.Fatal(err)
+		}
+		var age int32
+		if err := NewTable(db, "man").Select("age").Where("id=?", m.Id).FindOne(&age); err != nil {
+			t.Fatal(err)
+		}
+		if !test.Equal(age, int32(2)) {
+			t.Error(test.NoEqErr)
+		}
+	})
+}
+
+func TestReturningOnlyInsert(t *testing.T) {
+	mdb, _ := newMockDb(func(query string, args []driver.Value) *mockResult {
+		if strings.HasPrefix(query, "SHOW COLUMNS") {
+			return mockManCols()
+		}
+		return nil
+	})
+	defer mdb.Close()
+
+	m := &test.Man{Name: sureName, Age: sureAge}
+	tables := map[string]*Table{
+		"insert ignore":  NewTable(mdb, "man").InsertsIg(m),
+		"insert replace": NewTable(mdb, "man").InsertsRp(m),
+		"upsert":         NewTable(mdb, "man").Upsert([]any{m}, UpsertOpts{DoNothing: true}),
+	}
+	for name, table := range tables {
+		_, err := table.Returning().Exec()
+		if err == nil || !strings.Contains(err.Error(), "returning only support insert") {
+			t.Errorf("%s returning should be failed, err: %v", name, err)
+		}
+	}
+}
+
+func TestBackfillAutoIncIncrement(t *testing.T) {
+	mdb, _ := newMockDb(func(query string, args []driver.Value) *mockResult {
+		if strings.HasPrefix(query, "SHOW COLUMNS") {
+			return mockManCols()
+		}
+		return &mockResult{lastInsertId: 11, rowsAffected: 3}
+	})
+	defer mdb.Close()
+
+	ms := []*test.Man{{Name: sureName, Age: sureAge}, {Name: sureName, Age: sureAge}, {Name: sureName, Age: sureAge}}
+	_, err := NewTable(mdb, "man").AutoIncLockMode(AutoIncLockModeConsecutive).AutoIncIncrement(2).Insert(ms[0], ms[1], ms[2]).Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range ms {
+		if want := int32(11 + 2*i); m.Id != want {
+			t.Errorf("ms[%d].Id got: %d, want: %d", i, m.Id, want)
+		}
+	}
+}
+
+type HookMan struct {
+	Id   int32  `json:"id"`
+	Name string `json:"name"`
+	Age  int32  `json:"age"`
+}
+
+func (h *HookMan) BeforeInsert(ctx context.Context) error {
+	h.Name = strings.TrimSpace(h.Name)
+	if h.Name == "" {
+		return errors.New("name is empty")
+	}
+	return nil
+}
+
+func (h *HookMan) BeforeUpdate(ctx context.Context) error {
+	h.Name = strings.TrimSpace(h.Name)
+	return nil
+}
+
+func (h *HookMan) BeforeDelete(ctx context.Context) error {
+	if h.Id == 0 {
+		return errors.New("id is empty")
+	}
+	return nil
+}
+
+func (h *HookMan) AfterFind(ctx context.Context) error {
+	h.Name = "hook:" + h.Name
+	return nil
+}
+
+func TestHook(t *testing.T) {
+	t.Run("before insert", func(t *testing.T) {
+		_, err := NewTable(db, "man").Insert(&HookMan{Name: "  "}).Exec()
+		if err == nil {
+			t.Error("it should is err")
+		}
+
+		_, err = NewTable(db, "man").Insert(&HookMan{Name: " " + sureName + " "}).Exec()
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("after find", func(t *testing.T) {
+		var res []*HookMan
+		err := NewTable(db, "man").SelectAuto(HookMan{}).Where("name=?", sureName).Limit(1, 1).FindAll(&res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) == 0 || !test.Equal(res[0].Name, "hook:"+sureName) {
+			t.Error(test.NoEqErr)
+		}
+	})
+
+	t.Run("before delete", func(t *testing.T) {
+		_, err := NewTable(db, "man").Delete(&HookMan{Name: sureName}).Exec()
+		if err == nil {
+			t.Error("it should is err")
+		}
+	})
+}
+
+func TestSoftDelete(t *testing.T) {
+	type SoftMan struct {
+		Id   int32  `json:"id"`
+		Name string `json:"name"`
+	}
+
+	m := &SoftMan{Name: sureName}
+	if _, err := NewTable(db, "soft_man").Insert(m).Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("delete", func(t *testing.T) {
+		_, err := NewTable(db, "soft_man").SoftDelete().DeleteWhere("id=?", m.Id).Exec()
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("find", func(t *testing.T) {
+		var res SoftMan
+		err := NewTable(db, "soft_man").SoftDelete().FindWhere(&res, "id=?", m.Id)
+		if !IsNullRow(err) {
+			t.Error("it should be deleted, err:", err)
+		}
+
+		var total int
+		_ = NewTable(db, "soft_man").SoftDelete().SelectCount().Where("id=?", m.Id).Count(&total)
+		if !test.Equal(total, 0) {
+			t.Error(test.NoEqErr)
+		}
+	})
+
+	t.Run("unscoped", func(t *testing.T) {
+		var res SoftMan
+		err := NewTable(db, "soft_man").SoftDelete().Unscoped().FindWhere(&res, "id=?", m.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !test.Equal(res.Name, sureName) {
+			t.Error(test.NoEqErr)
+		}
+
+		if _, err := NewTable(db, "soft_man").SoftDelete().Unscoped().DeleteWhere("id=?", m.Id).Exec(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
 func TestUpdate(t *testing.T) {
 	InitTestMain(t, 10)
 	m := test.Man{
@@ -0,0 +1,94 @@
+package spellsql
+
+import (
+	"time"
+
+	"gitee.com/xuesongtao/spellsql/v2/internal"
+)
+
+// AutoTimestamp 开启自动维护时间列, cols[0] 为创建时间列, cols[1] 为更新时间列, 默认: created_at, updated_at
+// 开启后:
+//
+//	1.Insert 时如果创建/更新时间列为零值(或结构体中没有该字段), 会填充为当前时间
+//	2.Update 时会将更新时间列设置为当前时间
+//	3.InsertODKU 时会将更新时间列追加到冲突更新的列中
+//
+// 注: 表中没有对应列时不处理, 列名传空字符串表示不处理该列, 如: AutoTimestamp("", "updated_at")
+func (t *Table) AutoTimestamp(cols ...string) *Table {
+	t.autoTimestamp = true
+	t.createdAtCol, t.updatedAtCol = getAutoTimestampCols(cols...)
+	return t
+}
+
+// NowFn 设置获取当前时间的方法, 用于自动时间列和软删除, 默认: time.Now
+func (t *Table) NowFn(fn func() time.Time) *Table {
+	if fn == nil {
+		return t
+	}
+	t.nowFn = fn
+	return t
+}
+
+// getAutoTimestampCols 解析自动时间列
+func getAutoTimestampCols(cols ...string) (createdAtCol, updatedAtCol string) {
+	createdAtCol, updatedAtCol = internal.CreatedAtCol, internal.UpdatedAtCol
+	switch len(cols) {
+	case 0:
+	case 1:
+		createdAtCol = cols[0]
+	default:
+		createdAtCol, updatedAtCol = cols[0], cols[1]
+	}
+	return
+}
+
+// now 获取当前时间
+func (t *Table) now() time.Time {
+	if t.nowFn == nil {
+		return time.Now()
+	}
+	return t.nowFn()
+}
+
+// getAutoTimestampCols 获取 op 需要自动填充时间的列, 表中没有的列会被过滤
+func (t *Table) getAutoTimestampCols(op uint8) []string {
+	if !t.autoTimestamp {
+		return nil
+	}
+
+	var cols []string
+	switch op {
+	case internal.INSERT, internal.INSERT_REPLACE, internal.INSERT_IGNORE, internal.INSERT_ON_DUPLICATE:
+		cols = []string{t.createdAtCol, t.updatedAtCol}
+	case internal.UPDATE:
+		cols = []string{t.updatedAtCol}
+	default:
+		return nil
+	}
+
+	res := make([]string, 0, len(cols))
+	for _, col := range cols {
+		if col == "" {
+			continue
+		}
+		if _, ok := t.cacheCol2InfoMap[col]; !ok {
+			continue
+		}
+		res = append(res, col)
+	}
+	return res
+}
+
+// appendUpdatedAtCol 将更新时间列追加到 cols 中, 用于 InsertODKU
+func (t *Table) appendUpdatedAtCol(cols []string) []string {
+	autoCols := t.getAutoTimestampCols(internal.UPDATE)
+	if len(autoCols) == 0 {
+		return cols
+	}
+	for _, col := range cols {
+		if col == autoCols[0] {
+			return cols
+		}
+	}
+	return append(cols, autoCols[0])
+}
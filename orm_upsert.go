@@ -0,0 +1,95 @@
+package spellsql
+
+import (
+	"fmt"
+
+	"gitee.com/xuesongtao/spellsql/v2/builder"
+	"gitee.com/xuesongtao/spellsql/v2/dialect"
+	"gitee.com/xuesongtao/spellsql/v2/internal"
+)
+
+// UpsertOpts 新增冲突时的处理, 见 Table.Upsert
+type UpsertOpts struct {
+	ConflictCols []string          // 冲突的列, 默认: 主键
+	UpdateCols   []string          // 冲突时使用新值更新的列, 和 UpdateExprs 都为空时更新除冲突列外新增的所有列
+	UpdateExprs  map[string]string // 冲突时使用表达式更新的列, key: 列名, value: 表达式(原样输出), 如: {"num": "num + 1"}
+	DoNothing    bool              // 冲突时不处理
+}
+
+// Upsert 新增, 冲突时更新或不处理, 会根据 dbType 生成对应的语法:
+//
+//	MySQL: ON DUPLICATE KEY UPDATE `name`=VALUES(`name`)
+//	Postgres/SQLite: ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name" / ON CONFLICT ("id") DO NOTHING
+//	其他: 方言需要实现 dialect.Upserter
+//
+// 如: NewTable(db).Upsert([]any{&man}, UpsertOpts{UpdateCols: []string{"name"}}).Exec()
+// 注: 开启 AutoTimestamp 时, 默认更新的列中不包含创建时间列, 指定 UpdateCols 时会追加更新时间列
+func (t *Table) Upsert(insertObjs []any, opts ...UpsertOpts) *Table {
+	handleCols, err := t.insert(internal.INSERT, nil, insertObjs...)
+	if err != nil {
+		t.err = err
+		return t
+	}
+	t.insertOp = internal.INSERT_ON_DUPLICATE // 冲突更新时 LastInsertId 不准确, 不回填自增主键
+
+	var opt UpsertOpts
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if err := t.setUpsert(handleCols, opt); err != nil {
+		t.err = err
+	}
+	return t
+}
+
+// setUpsert 设置 Insert 冲突时的处理, handleCols 为新增的列
+func (t *Table) setUpsert(handleCols []string, opt UpsertOpts) error {
+	clause := &dialect.UpsertClause{
+		ConflictCols: opt.ConflictCols,
+		UpdateCols:   opt.UpdateCols,
+		UpdateExprs:  opt.UpdateExprs,
+		DoNothing:    opt.DoNothing,
+	}
+	if len(clause.ConflictCols) == 0 {
+		clause.ConflictCols = t.getPriCols()
+	}
+
+	if !clause.DoNothing {
+		if len(clause.UpdateCols) > 0 {
+			clause.UpdateCols = t.appendUpdatedAtCol(clause.UpdateCols)
+		} else if len(clause.UpdateExprs) == 0 {
+			clause.UpdateCols = t.getUpsertUpdateCols(handleCols, clause.ConflictCols)
+		}
+	}
+
+	upsertDialect, err := dialect.GetDialect(t.dbType)
+	if err != nil {
+		return err
+	}
+	sqlStr, err := dialect.UpsertSql(upsertDialect, clause)
+	if err != nil {
+		return fmt.Errorf("upsert is failed, table: %s, err: %v", t.name, err)
+	}
+	t.builder.(*builder.Insert).OnConflict(sqlStr)
+	return nil
+}
+
+// getUpsertUpdateCols 获取默认冲突时更新的列, 排除冲突列和创建时间列
+func (t *Table) getUpsertUpdateCols(handleCols, conflictCols []string) []string {
+	skipColMap := make(map[string]bool, len(conflictCols)+1)
+	for _, col := range conflictCols {
+		skipColMap[col] = true
+	}
+	if t.autoTimestamp && t.createdAtCol != "" {
+		skipColMap[t.createdAtCol] = true
+	}
+
+	cols := make([]string, 0, len(handleCols))
+	for _, col := range handleCols {
+		if skipColMap[col] {
+			continue
+		}
+		cols = append(cols, col)
+	}
+	return cols
+}
@@ -0,0 +1,105 @@
+package spellsql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"gitee.com/xuesongtao/spellsql/v2/builder"
+	"gitee.com/xuesongtao/spellsql/v2/dialect"
+	"gitee.com/xuesongtao/spellsql/v2/internal"
+	"gitee.com/xuesongtao/spellsql/v2/utils"
+)
+
+// Version 开启乐观锁, col 为版本号列名, 默认: version
+// 开启后 Update 会添加: SET version = version + 1 WHERE xxx AND version = 对象当前的版本号
+// 执行后影响行数为 0 时返回 ErrStaleObject, 成功时如果对象为指针会将版本号 +1
+// 如: NewTable(db).Version().Update(&cfg, "id = ?", cfg.Id).Exec()
+func (t *Table) Version(col ...string) *Table {
+	t.versionCol = internal.VersionCol
+	if len(col) > 0 && col[0] != "" {
+		t.versionCol = col[0]
+	}
+	return t
+}
+
+// setVersionLock 为 Update 添加乐观锁
+func (t *Table) setVersionLock(updateObj any, updateBuilder *builder.Update) error {
+	if _, ok := t.cacheCol2InfoMap[t.versionCol]; !ok {
+		return fmt.Errorf("version col %q is not exist in table %s", t.versionCol, t.name)
+	}
+	val, ok := t.getStructFieldByCol(updateObj, t.versionCol)
+	if !ok {
+		return fmt.Errorf("version col %q is not found in struct", t.versionCol)
+	}
+
+	gd, err := dialect.GetDialect(t.dbType)
+	if err != nil {
+		return err
+	}
+	updateBuilder.SetExpr(t.versionCol, dialect.WarpCol(gd, t.versionCol)+" + 1")
+
+	// 原有条件需要作为一个整体, 防止 OR 条件导致乐观锁失效
+	where := builder.NewWhere(t.dbType)
+	if oldWhere := updateBuilder.Where(); oldWhere != nil && !oldWhere.Empty() {
+		where.AndGroup(oldWhere)
+	}
+	where.Eq(t.versionCol, val.Interface())
+	updateBuilder.SetWhere(where)
+	t.versionObj = updateObj
+	return nil
+}
+
+// checkVersionLock 检查乐观锁是否更新成功, 成功时回填版本号
+func (t *Table) checkVersionLock(res sql.Result) error {
+	if t.versionObj == nil {
+		return nil
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w, table: %s", ErrStaleObject, t.name)
+	}
+
+	// 回填版本号, 方便继续更新
+	if reflect.ValueOf(t.versionObj).Kind() != reflect.Ptr {
+		return nil
+	}
+	val, _ := t.getStructFieldByCol(t.versionObj, t.versionCol)
+	if !val.CanSet() {
+		return nil
+	}
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val.SetInt(val.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val.SetUint(val.Uint() + 1)
+	}
+	return nil
+}
+
+// getStructFieldByCol 根据列名获取结构体字段
+func (t *Table) getStructFieldByCol(v any, col string) (reflect.Value, bool) {
+	return t.getFieldValueByCol(utils.RemoveValuePtr(reflect.ValueOf(v)), col)
+}
+
+// getFieldValueByCol 根据列名获取结构体字段, tv 为结构体, 指针/sql.NullInt64 等对象字段也会按 tag 匹配
+func (t *Table) getFieldValueByCol(tv reflect.Value, col string) (reflect.Value, bool) {
+	if tv.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	for _, fieldPath := range t.getStructFieldPaths(tv.Type()) {
+		fieldCol, tag, _ := t.parseStructField(fieldPath.field)
+		if fieldCol == "" && t.needSkipObj(fieldPath.field.Type.Kind()) {
+			fieldCol = tag
+		}
+		if fieldCol == col {
+			return utils.FieldByIndex(tv, fieldPath.index)
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -3,6 +3,7 @@ package spellsql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"gitee.com/xuesongtao/spellsql/v2/builder"
@@ -63,6 +64,11 @@ func IsNullRow(err error) bool {
 	return err == internal.NullRowErr
 }
 
+// IsStaleObject 是否为乐观锁更新失败
+func IsStaleObject(err error) bool {
+	return errors.Is(err, ErrStaleObject)
+}
+
 // ExecForSql 根据 sql 进行执行 INSERT/UPDATE/DELETE 等操作
 // sql sqlStr 或 *SqlStrObj
 func ExecForSql(db DBer, sql any) (sql.Result, error) {
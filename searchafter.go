@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	"gitee.com/xuesongtao/spellsql/v2/builder"
+	"gitee.com/xuesongtao/spellsql/v2/dialect"
 	"gitee.com/xuesongtao/spellsql/v2/utils"
 )
 
@@ -17,16 +19,24 @@ var (
 
 // SearchAfter
 type SearchAfter struct {
-	SqlStr   any                  // 查询 base sql, sqlStr 支持 string/*builder.Select, 只能包含到 where 部分, 注: 查询部分, 必须包含 names 里的字段
-	Table    string                       // 表名, 如果 sqlStr 是 *builder.Select, 则会自动获取表名
-	Names    []string                     // 排序的列名, 默认: id(只有在 sqlStr 为 *builder.Select 可用), 建议用索引值, Names, Values, OrderBys 的长度必须相等, 且顺序一致, 例如: names = ["id", "name"], values = [1, "test"]
-	Values   []any                // 分页值, 每次处理完后, 会自动根据查询结果里的值更新为最后一行的值, 以便下一次查询, 根据 大于 的条件进行查询
-	OrderBys []string                     // 按什么进行排序, 默认: id asc, 例如: ["id ASC", "name DESC"], 如果不传, 则默认按 names 里的字段进行升序排序
-	Size     int                          // 每次处理多少
-	Dest     any                  // scan 对象, 即回调里的对象
-	RowFn    func(_row any) error // 每行的回调函数
-
-	nameMap map[string]int // names 的 map, key: 字段名, value: 下标
+	SqlStr       any                  // 查询 base sql, sqlStr 支持 string/*builder.Select, 只能包含到 where 部分, 注: 查询部分, 必须包含 names 里的字段
+	Table        string               // 表名, 如果 sqlStr 是 *builder.Select, 则会自动获取表名
+	Names        []string             // 排序的列名, 默认: id(只有在 sqlStr 为 *builder.Select 可用), 建议用索引值, Names, Values, OrderBys 的长度必须相等, 且顺序一致, 例如: names = ["id", "name"], values = [1, "test"]
+	Values       []any                // 分页值, 每次处理完后, 会自动根据查询结果里的值更新为最后一行的值, 以便下一次查询, 根据每列的排序方向比较(ASC 为大于, DESC 为小于), 多列时按行比较, 如: (a, b) > (?, ?)
+	OrderBys     []string             // 按什么进行排序, 默认: id asc, 例如: ["id ASC", "name DESC"], 如果不传, 则默认按 names 里的字段进行升序排序
+	Size         int                  // 每次处理多少
+	Dest         any                  // scan 对象, 即回调里的对象
+	RowFn        func(_row any) error // 每行的回调函数
+	Checkpointer Checkpointer         // 分页值的保存点, 设置后 Search 开始时会加载上次保存的 Values, 每页处理完后保存, 用于中断后继续处理; 注: 中断页中已处理的行会被重复处理
+	CursorKey    []byte               // 游标签名的 key, 用于 EncodeCursor/DecodeCursor/NextPage
+	DbType       dialect.DbType       // 数据库类型, 用于包裹列名和生成分页语句, 为 0 时使用 dialect.DefaultDbType, 如果 sqlStr 是 *builder.Select, 则会使用其数据库类型
+
+	gd      dialect.Dialect // DbType 对应的方言
+	nameMap map[string]int  // names 的 map, key: 字段名, value: 下标
+	descs   []bool          // 每列是否为降序, 与 names 对应
+	pageFn  func()          // 每页处理完后的回调, 此时 Values 已更新, 用于 ParallelScan 上报进度
+	before  bool            // 是否查询分页值之前的数据, 用于 PrevPage, 比较符和排序方向都会反转
+	mu      sync.Mutex      // NextPage/PrevPage 渲染 SqlStr 时使用
 }
 
 func (s *SearchAfter) init() error {
@@ -39,6 +49,17 @@ func (s *SearchAfter) init() error {
 	}
 
 	selectBuilder, autoSet := s.SqlStr.(*builder.Select)
+	if autoSet {
+		s.DbType = selectBuilder.GetDbType()
+	} else if s.DbType == 0 {
+		s.DbType = dialect.DefaultDbType
+	}
+	gd, err := dialect.GetDialect(s.DbType)
+	if err != nil {
+		return err
+	}
+	s.gd = gd
+
 	if len(s.Names) == 0 {
 		if autoSet {
 			s.Names = []string{"id"}
@@ -70,6 +91,12 @@ func (s *SearchAfter) init() error {
 		return errors.New("sqlStr no contains order/group, it only have where")
 	}
 
+	s.descs = make([]bool, len(s.OrderBys))
+	for i, orderBy := range s.OrderBys {
+		fields := strings.Fields(orderBy)
+		s.descs[i] = len(fields) > 1 && strings.EqualFold(fields[len(fields)-1], "DESC")
+	}
+
 	s.nameMap = make(map[string]int)
 	var newSelectBuilder *builder.Select
 	for i, name := range s.Names {
@@ -103,29 +130,112 @@ func (s *SearchAfter) getSqlStr() string {
 }
 
 func (s *SearchAfter) reGetSelectBuilder() *builder.Select {
-	selectObj := builder.NewSelect()
+	selectObj := builder.NewSelect(s.DbType)
 	selectObj.InitSql2Args(s.getSqlStr())
 	selectObj.WhereCb(func(wb *builder.Where) {
-		for i, name := range s.Names {
-			wb.Gt(name, s.Values[i])
-		}
+		cond, args := s.getKeysetCond()
+		wb.And(cond, args...)
 	})
-	selectObj.OrderBy(strings.Join(s.OrderBys, ", "))
+	selectObj.OrderBy(strings.Join(s.getOrderBys(), ", "))
 	selectObj.Limit(0, s.Size)
 	return selectObj
 }
 
+// getKeysetCond 获取分页条件, 每列按各自的排序方向比较, 如: OrderBys = ["a ASC", "b DESC"]
+//
+//	(`a` > ? OR (`a` = ? AND `b` < ?))
+//
+// 所有列的排序方向一致且数据库支持行值比较时, 如: (`a`, `b`) > (?, ?)
+func (s *SearchAfter) getKeysetCond() (string, []any) {
+	var (
+		cols    = make([]string, len(s.Names))
+		sameDir = true
+	)
+	for i, name := range s.Names {
+		cols[i] = dialect.WarpCol(s.gd, name)
+		if s.descs[i] != s.descs[0] {
+			sameDir = false
+		}
+	}
+	if len(cols) == 1 {
+		return cols[0] + s.getCompareOp(0) + dialect.Placeholders(), []any{s.Values[0]}
+	}
+
+	switch s.DbType {
+	case dialect.MySQL, dialect.Postgres, dialect.SQLite:
+		if sameDir {
+			return "(" + strings.Join(cols, ", ") + ")" + s.getCompareOp(0) + "(" + dialect.Placeholders(len(cols)) + ")", s.Values
+		}
+	}
+
+	// 展开为: a > ? OR (a = ? AND b > ?) OR ...
+	var (
+		ors  = make([]string, 0, len(cols))
+		args = make([]any, 0, len(cols)*(len(cols)+1)/2)
+	)
+	for i := range cols {
+		if i == 0 {
+			ors = append(ors, cols[i]+s.getCompareOp(i)+dialect.Placeholders())
+			args = append(args, s.Values[i])
+			continue
+		}
+
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, cols[j]+" = "+dialect.Placeholders())
+			args = append(args, s.Values[j])
+		}
+		ands = append(ands, cols[i]+s.getCompareOp(i)+dialect.Placeholders())
+		args = append(args, s.Values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// getCompareOp 获取第 i 列的比较符, before 时反转
+func (s *SearchAfter) getCompareOp(i int) string {
+	if s.descs[i] != s.before {
+		return " < "
+	}
+	return " > "
+}
+
+// getOrderBys 获取排序, before 时反转每列的排序方向, 如: ["id ASC", "name DESC"] => ["id DESC", "name ASC"]
+func (s *SearchAfter) getOrderBys() []string {
+	if !s.before {
+		return s.OrderBys
+	}
+
+	orderBys := make([]string, len(s.OrderBys))
+	for i, orderBy := range s.OrderBys {
+		fields := strings.Fields(orderBy)
+		if last := fields[len(fields)-1]; len(fields) > 1 && (strings.EqualFold(last, "ASC") || strings.EqualFold(last, "DESC")) {
+			fields = fields[:len(fields)-1]
+		}
+		dir := " DESC"
+		if s.descs[i] {
+			dir = " ASC"
+		}
+		orderBys[i] = strings.Join(fields, " ") + dir
+	}
+	return orderBys
+}
+
 // SearchAfter 统一根据唯一值进行分页
 func (s *SearchAfter) Search(ctx context.Context, db DBer) error {
 	if err := s.init(); err != nil {
 		return err
 	}
+	if err := s.loadCheckpoint(ctx); err != nil {
+		return err
+	}
 
 	total := 0
 	for {
 		rowCount := 0
 		var lastRow any
 		err := NewTable(db, s.Table).
+			DbType(s.DbType).
 			Ctx(ctx).
 			Raw(s.reGetSelectBuilder()).
 			FindOneIgnoreResult(
@@ -149,19 +259,58 @@ func (s *SearchAfter) Search(ctx context.Context, db DBer) error {
 		}
 		total += rowCount
 		sLog.Info(ctx, "searched rowCount:", rowCount, "total:", total)
-		if rowCount < s.Size {
-			break
-		}
-
 		if lastRow != nil {
 			if err := s.initValues(lastRow); err != nil {
 				return err
 			}
+			if err := s.saveCheckpoint(ctx); err != nil {
+				return err
+			}
+			if s.pageFn != nil {
+				s.pageFn()
+			}
+		}
+
+		if rowCount < s.Size {
+			break
 		}
 	}
 	return nil
 }
 
+// loadCheckpoint 从保存点加载分页值
+func (s *SearchAfter) loadCheckpoint(ctx context.Context) error {
+	if s.Checkpointer == nil {
+		return nil
+	}
+
+	values, err := s.Checkpointer.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("load checkpoint is failed, err: %v", err)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if len(values) != len(s.Names) {
+		return fmt.Errorf("checkpoint values len %d, names len %d, they must equal", len(values), len(s.Names))
+	}
+	s.Values = values
+	sLog.Info(ctx, "search after resume from checkpoint:", values)
+	return nil
+}
+
+// saveCheckpoint 保存分页值
+func (s *SearchAfter) saveCheckpoint(ctx context.Context) error {
+	if s.Checkpointer == nil {
+		return nil
+	}
+
+	if err := s.Checkpointer.Save(ctx, s.Values); err != nil {
+		return fmt.Errorf("save checkpoint is failed, err: %v", err)
+	}
+	return nil
+}
+
 func (s *SearchAfter) initValues(row any) error {
 	rets, err := utils.ParseStructField(reflect.ValueOf(row))
 	if err != nil {
@@ -0,0 +1,160 @@
+package spellsql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Checkpointer 保存/加载 SearchAfter 的分页值(Values), 用于中断后从上次处理完的页继续
+type Checkpointer interface {
+	Load(ctx context.Context) ([]any, error)      // 加载分页值, 没有时返回 nil, nil
+	Save(ctx context.Context, values []any) error // 每页处理完后保存分页值
+}
+
+// FileCheckpointer 基于文件的 Checkpointer, 保存时先写临时文件再重命名, 防止写一半时崩溃导致文件损坏
+// 支持的值类型: 整型/浮点型/字符串/[]byte/time.Time/nil
+type FileCheckpointer struct {
+	Path string
+}
+
+// NewFileCheckpointer 创建基于文件的 Checkpointer
+func NewFileCheckpointer(path string) *FileCheckpointer {
+	return &FileCheckpointer{Path: path}
+}
+
+// typedValue 带类型的分页值, 防止 json 反序列化后类型改变, 如: int64 => float64, 保存点和游标共用
+type typedValue struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Load 文件不存在时返回 nil, nil
+func (f *FileCheckpointer) Load(ctx context.Context) ([]any, error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var typedValues []typedValue
+	if err := json.Unmarshal(data, &typedValues); err != nil {
+		return nil, fmt.Errorf("checkpoint %q unmarshal is failed, err: %v", f.Path, err)
+	}
+	values, err := decodeTypedValues(typedValues)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint %q decode is failed, err: %v", f.Path, err)
+	}
+	return values, nil
+}
+
+// Save 覆盖保存分页值
+func (f *FileCheckpointer) Save(ctx context.Context, values []any) error {
+	typedValues, err := encodeTypedValues(values)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(typedValues)
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), f.Path)
+}
+
+// encodeTypedValues 将分页值转为带类型的值
+func encodeTypedValues(values []any) ([]typedValue, error) {
+	typedValues := make([]typedValue, len(values))
+	for i, val := range values {
+		v, err := encodeTypedValue(val)
+		if err != nil {
+			return nil, err
+		}
+		typedValues[i] = v
+	}
+	return typedValues, nil
+}
+
+// decodeTypedValues 将带类型的值转为分页值
+func decodeTypedValues(typedValues []typedValue) ([]any, error) {
+	values := make([]any, len(typedValues))
+	for i, v := range typedValues {
+		val, err := v.decode()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = val
+	}
+	return values, nil
+}
+
+// encodeTypedValue 将分页值转为带类型的值
+func encodeTypedValue(val any) (typedValue, error) {
+	if val == nil {
+		return typedValue{Type: "nil"}, nil
+	}
+	switch v := val.(type) {
+	case time.Time:
+		return typedValue{Type: "time", Value: v.Format(time.RFC3339Nano)}, nil
+	case []byte:
+		return typedValue{Type: "bytes", Value: string(v)}, nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return typedValue{Type: "int", Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return typedValue{Type: "uint", Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return typedValue{Type: "float", Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	case reflect.String:
+		return typedValue{Type: "string", Value: rv.String()}, nil
+	}
+	return typedValue{}, fmt.Errorf("value type %T is not support", val)
+}
+
+// decode 转为分页值
+func (v typedValue) decode() (any, error) {
+	switch v.Type {
+	case "nil":
+		return nil, nil
+	case "time":
+		return time.Parse(time.RFC3339Nano, v.Value)
+	case "bytes":
+		return []byte(v.Value), nil
+	case "int":
+		return strconv.ParseInt(v.Value, 10, 64)
+	case "uint":
+		return strconv.ParseUint(v.Value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(v.Value, 64)
+	case "string":
+		return v.Value, nil
+	}
+	return nil, fmt.Errorf("value type %q is unknown", v.Type)
+}
@@ -0,0 +1,237 @@
+package spellsql
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	InvalidCursorErr = errors.New("cursor is invalid")
+)
+
+// cursorPayload 游标中保存的分页状态
+type cursorPayload struct {
+	Names    []string     `json:"n"`
+	Values   []typedValue `json:"v"`
+	OrderBys []string     `json:"o"`
+	SqlHash  string       `json:"s"` // base sql 的 hash, 防止使用相同列但条件不同的查询的游标
+}
+
+// SearchPage 单页查询的结果
+type SearchPage struct {
+	Rows       []any  // 查询结果, 每行为 Dest 类型的新对象, 按 OrderBys 排序
+	HasPrev    bool   // 是否有上一页
+	HasNext    bool   // 是否有下一页
+	PrevCursor string // 上一页的游标(当前页第一行), 用于 PrevPage, 没有上一页时为空
+	NextCursor string // 下一页的游标(当前页最后一行), 用于 NextPage, 没有下一页时为空
+}
+
+// EncodeCursor 将 Names/Values/OrderBys 及 base sql 的 hash 编码为签名后的游标, 格式: base64(状态).base64(HMAC-SHA256 签名)
+// Values 的类型会保留, 支持的类型同 FileCheckpointer
+func (s *SearchAfter) EncodeCursor() (string, error) {
+	if len(s.CursorKey) == 0 {
+		return "", errors.New("cursor key required")
+	}
+
+	values, err := encodeTypedValues(s.Values)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(cursorPayload{Names: s.Names, Values: values, OrderBys: s.OrderBys, SqlHash: s.getSqlHash()})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(s.signCursor(data)), nil
+}
+
+// DecodeCursor 校验游标签名, 并解析到 Names/Values/OrderBys, 游标被篡改时返回 InvalidCursorErr
+// 设置了 SqlStr 时会校验游标是否为同一个 base sql 生成的
+func (s *SearchAfter) DecodeCursor(cursor string) error {
+	if len(s.CursorKey) == 0 {
+		return errors.New("cursor key required")
+	}
+
+	dataStr, signStr, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return InvalidCursorErr
+	}
+	data, err := base64.RawURLEncoding.DecodeString(dataStr)
+	if err != nil {
+		return InvalidCursorErr
+	}
+	sign, err := base64.RawURLEncoding.DecodeString(signStr)
+	if err != nil || !hmac.Equal(sign, s.signCursor(data)) {
+		return InvalidCursorErr
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("%w, unmarshal is failed, err: %v", InvalidCursorErr, err)
+	}
+	values, err := decodeTypedValues(payload.Values)
+	if err != nil {
+		return fmt.Errorf("%w, decode is failed, err: %v", InvalidCursorErr, err)
+	}
+	if len(payload.Names) != len(values) || len(payload.Names) != len(payload.OrderBys) {
+		return fmt.Errorf("%w, names, values, orderBys len must equal", InvalidCursorErr)
+	}
+	if s.SqlStr != nil && payload.SqlHash != s.getSqlHash() {
+		return fmt.Errorf("%w, sql is not match", InvalidCursorErr)
+	}
+	s.Names, s.Values, s.OrderBys = payload.Names, values, payload.OrderBys
+	return nil
+}
+
+// getSqlHash 获取 base sql 的 hash
+func (s *SearchAfter) getSqlHash() string {
+	sum := sha256.Sum256([]byte(s.getSqlStr()))
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// signCursor 游标签名
+func (s *SearchAfter) signCursor(data []byte) []byte {
+	mac := hmac.New(sha256.New, s.CursorKey)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// NextPage 查询游标后的一页, cursor 为空时查询第一页(从 Values 开始), size <= 0 时使用 Size
+// 游标中的 Names/OrderBys 必须和当前的一致, 防止使用其他查询的游标
+// 注: 不会修改 s 的 Values, 可以并发调用, 不会调用 RowFn 和 Checkpointer
+func (s *SearchAfter) NextPage(ctx context.Context, db DBer, cursor string, size int) (*SearchPage, error) {
+	return s.searchPage(ctx, db, cursor, size, false)
+}
+
+// PrevPage 查询游标前的一页, 一般传入 SearchPage.PrevCursor, 查询时反转比较符和排序方向, 结果会恢复为 OrderBys 的顺序
+// 其他同 NextPage
+func (s *SearchAfter) PrevPage(ctx context.Context, db DBer, cursor string, size int) (*SearchPage, error) {
+	if cursor == "" {
+		return nil, errors.New("cursor required")
+	}
+	return s.searchPage(ctx, db, cursor, size, true)
+}
+
+// searchPage 查询单页, before 为 true 时查询游标前的一页
+func (s *SearchAfter) searchPage(ctx context.Context, db DBer, cursor string, size int, before bool) (*SearchPage, error) {
+	sa, err := s.getPageSearchAfter(cursor, size)
+	if err != nil {
+		return nil, err
+	}
+	sa.before = before
+
+	rows, err := sa.findPage(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	haveMore := len(rows) > sa.Size-1 // 多查一行判断查询方向上是否还有数据
+	if haveMore {
+		rows = rows[:sa.Size-1]
+	}
+	page := &SearchPage{Rows: rows}
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	if before {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+		page.HasPrev, page.HasNext = haveMore, true // 游标所在行在当前页后
+	} else {
+		page.HasPrev, page.HasNext = cursor != "", haveMore
+	}
+	if page.HasPrev {
+		if page.PrevCursor, err = sa.getRowCursor(rows[0]); err != nil {
+			return nil, err
+		}
+	}
+	if page.HasNext {
+		if page.NextCursor, err = sa.getRowCursor(rows[len(rows)-1]); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// getPageSearchAfter 生成一个用于单页查询的 SearchAfter, 并根据游标设置分页值, 每页会多查一行
+func (s *SearchAfter) getPageSearchAfter(cursor string, size int) (*SearchAfter, error) {
+	sa, err := s.newPageSearchAfter()
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		size = sa.Size
+	}
+	sa.Size = size + 1
+
+	if cursor == "" {
+		return sa, nil
+	}
+	cursorSa := &SearchAfter{SqlStr: sa.SqlStr, CursorKey: s.CursorKey}
+	if err := cursorSa.DecodeCursor(cursor); err != nil {
+		return nil, err
+	}
+	if strings.Join(cursorSa.Names, ",") != strings.Join(sa.Names, ",") || strings.Join(cursorSa.OrderBys, ",") != strings.Join(sa.OrderBys, ",") {
+		return nil, fmt.Errorf("%w, names/orderBys is not match", InvalidCursorErr)
+	}
+	sa.Values = cursorSa.Values
+	return sa, nil
+}
+
+// newPageSearchAfter 根据 s 生成一个初始化后的 SearchAfter, SqlStr 会被渲染为 string
+// 渲染时会修改 *builder.Select 的内部状态, 所以需要加锁, 防止并发调用 NextPage/PrevPage 时共用同一个 builder
+func (s *SearchAfter) newPageSearchAfter() (*SearchAfter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sa := &SearchAfter{
+		SqlStr:    s.SqlStr,
+		Table:     s.Table,
+		Names:     append([]string(nil), s.Names...),
+		Values:    append([]any(nil), s.Values...),
+		OrderBys:  append([]string(nil), s.OrderBys...),
+		Size:      s.Size,
+		Dest:      s.Dest,
+		CursorKey: s.CursorKey,
+		DbType:    s.DbType,
+	}
+	if err := sa.init(); err != nil {
+		return nil, err
+	}
+	sa.SqlStr = sa.getSqlStr()
+	return sa, nil
+}
+
+// findPage 查询一页
+func (s *SearchAfter) findPage(ctx context.Context, db DBer) ([]any, error) {
+	rows := make([]any, 0, s.Size)
+	err := NewTable(db, s.Table).
+		DbType(s.DbType).
+		Ctx(ctx).
+		Raw(s.reGetSelectBuilder()).
+		FindOneIgnoreResult(
+			s.Dest,
+			func(_row any) error {
+				rows = append(rows, _row)
+				return nil
+			},
+		)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// getRowCursor 获取以 row 为分页值的游标
+func (s *SearchAfter) getRowCursor(row any) (string, error) {
+	if err := s.initValues(row); err != nil {
+		return "", err
+	}
+	return s.EncodeCursor()
+}
@@ -0,0 +1,304 @@
+package spellsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gitee.com/xuesongtao/spellsql/v2/builder"
+	"gitee.com/xuesongtao/spellsql/v2/dialect"
+)
+
+// ParallelScan 并行扫描, 将 KeyCol 的范围分为多个分区, 每个分区使用一个 SearchAfter 并发处理, 用于全表处理的任务
+type ParallelScan struct {
+	SqlStr        any                                 // 查询 base sql, 同 SearchAfter.SqlStr, 注: 查询部分, 必须包含 KeyCol
+	Table         string                              // 表名, 如果 sqlStr 是 *builder.Select, 则会自动获取表名
+	KeyCol        string                              // 分区的列, 必须为整型且唯一, 建议用主键, 默认: id
+	Partitions    int                                 // 分区数, 默认: 4, 设置 Boundaries 时为 len(Boundaries)-1
+	Boundaries    []int64                             // 分区边界, 升序, 如: [1, 100, 200] 分为 [1, 100), [100, 200] 两个分区, 不设置时按 KeyCol 的 MIN/MAX 均分
+	Workers       int                                 // 同时处理的分区数, 默认: Partitions
+	Size          int                                 // 每个分区每次处理多少, 默认: 10
+	Dest          any                                 // scan 对象, 即回调里的对象
+	RowFn         func(partition int, _row any) error // 每行的回调函数, 会被多个分区并发调用, 需要保证并发安全, 返回 CusSearchAfterStop 时只停止当前分区
+	ProgressFn    func(progress PartitionProgress)    // 每个分区每处理完一页及处理完时的回调, 会被多个分区并发调用
+	ContinueOnErr bool                                // 某个分区失败时其他分区是否继续, 默认: 取消其他分区
+	DbType        dialect.DbType                      // 数据库类型, 同 SearchAfter.DbType
+
+	gd dialect.Dialect // DbType 对应的方言
+}
+
+// PartitionProgress 分区的处理进度
+type PartitionProgress struct {
+	Partition int   // 分区下标
+	Start     int64 // 分区起始值(包含)
+	End       int64 // 分区结束值, 最后一个分区包含, 其他分区不包含
+	Total     int   // 已处理的行数
+	LastKey   any   // 最后处理的 KeyCol 值
+	Done      bool  // 是否处理完
+}
+
+// PartitionErr 单个分区的错误
+type PartitionErr struct {
+	Partition int
+	Start     int64
+	End       int64
+	Err       error
+}
+
+func (e *PartitionErr) Error() string {
+	return fmt.Sprintf("partition %d [%d, %d] is failed, err: %v", e.Partition, e.Start, e.End, e.Err)
+}
+
+func (e *PartitionErr) Unwrap() error {
+	return e.Err
+}
+
+// ParallelScanErr 所有失败分区的错误
+type ParallelScanErr struct {
+	Errs []*PartitionErr
+}
+
+func (e *ParallelScanErr) Error() string {
+	return fmt.Sprintf("%d partitions is failed, first %v", len(e.Errs), e.Errs[0])
+}
+
+func (e *ParallelScanErr) Unwrap() []error {
+	errs := make([]error, len(e.Errs))
+	for i, err := range e.Errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+// partition 分区
+type partition struct {
+	index int
+	start int64
+	end   int64
+	last  bool // 最后一个分区包含 end
+
+	sqlStr string // 分区的查询 sql, 启动分区前生成
+}
+
+func (p *ParallelScan) init() error {
+	if p.RowFn == nil {
+		return errors.New("rowFn required")
+	}
+	if p.KeyCol == "" {
+		p.KeyCol = "id"
+	}
+	if selectBuilder, ok := p.SqlStr.(*builder.Select); ok {
+		p.Table = selectBuilder.GetTableName()
+		p.DbType = selectBuilder.GetDbType()
+	} else if p.DbType == 0 {
+		p.DbType = dialect.DefaultDbType
+	}
+	gd, err := dialect.GetDialect(p.DbType)
+	if err != nil {
+		return err
+	}
+	p.gd = gd
+	if p.Table == "" {
+		return errors.New("table required")
+	}
+	if p.Partitions <= 0 {
+		p.Partitions = 4
+	}
+	if len(p.Boundaries) > 0 {
+		if len(p.Boundaries) < 2 {
+			return errors.New("boundaries len must gte 2")
+		}
+		for i := 1; i < len(p.Boundaries); i++ {
+			if p.Boundaries[i] <= p.Boundaries[i-1] {
+				return errors.New("boundaries must be ascending")
+			}
+		}
+		p.Partitions = len(p.Boundaries) - 1
+	}
+	if p.Workers <= 0 || p.Workers > p.Partitions {
+		p.Workers = p.Partitions
+	}
+	return nil
+}
+
+// Scan 并发处理所有分区, 失败的分区会汇总到 *ParallelScanErr 中
+// 注: ctx 取消时未处理完的分区会返回 ctx.Err()
+func (p *ParallelScan) Scan(ctx context.Context, db DBer) error {
+	if err := p.init(); err != nil {
+		return err
+	}
+	boundaries, err := p.getBoundaries(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(boundaries) == 0 { // 没有数据
+		return nil
+	}
+	baseSqlStr, err := p.getBaseSqlStr()
+	if err != nil {
+		return err
+	}
+	parts := make([]*partition, len(boundaries)-1)
+	for i := range parts {
+		parts[i] = &partition{index: i, start: boundaries[i], end: boundaries[i+1], last: i == len(parts)-1}
+		parts[i].sqlStr = p.getPartitionSqlStr(baseSqlStr, parts[i])
+	}
+
+	var (
+		scanCtx, cancel = context.WithCancel(ctx)
+		wg              sync.WaitGroup
+		mu              sync.Mutex
+		cancelled       bool // 因为某个分区失败而取消
+		scanErr         = &ParallelScanErr{}
+		sem             = make(chan struct{}, p.Workers)
+	)
+	defer cancel()
+	for _, part := range parts {
+		part := part
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := p.scanPartition(scanCtx, db, part)
+			if err == nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if cancelled && ctx.Err() == nil && errors.Is(err, context.Canceled) { // 被其他分区的错误取消, 不需要记录
+				return
+			}
+			scanErr.Errs = append(scanErr.Errs, &PartitionErr{Partition: part.index, Start: part.start, End: part.end, Err: err})
+			if !p.ContinueOnErr && !cancelled {
+				cancelled = true
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(scanErr.Errs) == 0 {
+		return nil
+	}
+	sort.Slice(scanErr.Errs, func(i, j int) bool { return scanErr.Errs[i].Partition < scanErr.Errs[j].Partition })
+	return scanErr
+}
+
+// getBoundaries 获取分区边界, 没有设置 Boundaries 时按 KeyCol 的 MIN/MAX 均分, 没有数据时返回 nil
+func (p *ParallelScan) getBoundaries(ctx context.Context, db DBer) ([]int64, error) {
+	if len(p.Boundaries) > 0 {
+		return p.Boundaries, nil
+	}
+
+	var (
+		minKey, maxKey sql.NullInt64
+		warpKeyCol     = dialect.WarpCol(p.gd, p.KeyCol)
+	)
+	sqlStr := "SELECT MIN(" + warpKeyCol + "), MAX(" + warpKeyCol + ") FROM " + p.Table
+	if err := db.QueryRowContext(ctx, sqlStr).Scan(&minKey, &maxKey); err != nil { // 表为空时为 NULL
+		return nil, fmt.Errorf("get %s min/max is failed, err: %v", p.KeyCol, err)
+	}
+	if !minKey.Valid || !maxKey.Valid {
+		return nil, nil
+	}
+	return splitBoundaries(minKey.Int64, maxKey.Int64, p.Partitions), nil
+}
+
+// splitBoundaries 将 [min, max] 均分为 n 个分区, 范围小于 n 时分区数会减少
+func splitBoundaries(min, max int64, n int) []int64 {
+	step := (max - min + int64(n)) / int64(n) // 向上取整
+	boundaries := make([]int64, 0, n+1)
+	boundaries = append(boundaries, min)
+	for i := 1; i < n; i++ {
+		cur := min + int64(i)*step
+		if cur >= max {
+			break
+		}
+		boundaries = append(boundaries, cur)
+	}
+	return append(boundaries, max)
+}
+
+// scanPartition 使用 SearchAfter 处理单个分区
+func (p *ParallelScan) scanPartition(ctx context.Context, db DBer, part *partition) error {
+	if err := ctx.Err(); err != nil { // 还未开始就被取消
+		return err
+	}
+
+	progress := PartitionProgress{Partition: part.index, Start: part.start, End: part.end}
+	s := &SearchAfter{
+		SqlStr: part.sqlStr,
+		Table:  p.Table,
+		DbType: p.DbType,
+		Names:  []string{p.KeyCol},
+		Values: []any{part.start - 1},
+		Size:   p.Size,
+		Dest:   p.Dest,
+		RowFn: func(_row any) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			progress.Total++
+			return p.RowFn(part.index, _row)
+		},
+	}
+	s.pageFn = func() {
+		progress.LastKey = s.Values[0]
+		p.onProgress(progress)
+	}
+	if err := s.Search(ctx, db); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil { // 取消时结果集会被提前关闭, 需要判断是否处理完
+		return err
+	}
+	progress.Done = true
+	p.onProgress(progress)
+	return nil
+}
+
+// getBaseSqlStr 获取 base sql, *builder.Select 会被渲染为 sql, 需要在启动分区前调用, 防止多个分区并发使用同一个 builder
+func (p *ParallelScan) getBaseSqlStr() (string, error) {
+	switch v := p.SqlStr.(type) {
+	case string:
+		if !strings.Contains(v, p.KeyCol) {
+			return "", fmt.Errorf("key col %q must contains in select", p.KeyCol)
+		}
+		return v, nil
+	case *builder.Select:
+		selectObj := v.GetNewSelectOfUntilWhere()
+		if !strings.Contains(v.GetSqlStr(), p.KeyCol) { // 同 SearchAfter 自动添加查询的列
+			selectObj.Select(p.KeyCol)
+		}
+		return selectObj.GetSqlStr(), nil
+	default:
+		return "", errors.New("sqlStr type must be string or *builder.Select")
+	}
+}
+
+// getPartitionSqlStr 在 base sql 上追加分区结束值的条件, 起始值由 SearchAfter 的分页值控制
+func (p *ParallelScan) getPartitionSqlStr(baseSqlStr string, part *partition) string {
+	op := " < "
+	if part.last {
+		op = " <= "
+	}
+	cond := dialect.WarpCol(p.gd, p.KeyCol) + op + dialect.Placeholders()
+	selectObj := builder.NewSelect(p.DbType)
+	selectObj.InitSql2Args(baseSqlStr)
+	selectObj.WhereCb(func(wb *builder.Where) { wb.And(cond, part.end) })
+	return selectObj.GetSqlStr()
+}
+
+func (p *ParallelScan) onProgress(progress PartitionProgress) {
+	if p.ProgressFn != nil {
+		p.ProgressFn(progress)
+	}
+}
@@ -2,10 +2,19 @@ package spellsql
 
 import (
 	"context"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"gitee.com/xuesongtao/spellsql/v2/builder"
+	"gitee.com/xuesongtao/spellsql/v2/dialect"
 )
 
 func TestSearchAfter(t *testing.T) {
@@ -108,3 +117,356 @@ func TestSearchAfter2ResultDemo(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestSearchAfterKeysetCond(t *testing.T) {
+	tests := []struct {
+		name     string
+		names    []string
+		orderBys []string
+		dbType   dialect.DbType
+		want     string
+	}{
+		{"one col", []string{"id"}, nil, 0, "`id` > ?"},
+		{"one col desc", []string{"id"}, []string{"id DESC"}, 0, "`id` < ?"},
+		{"row value", []string{"a", "b"}, []string{"a ASC", "b ASC"}, 0, "(`a`, `b`) > (?, ?)"},
+		{"row value desc", []string{"a", "b"}, []string{"a DESC", "b DESC"}, 0, "(`a`, `b`) < (?, ?)"},
+		{"mixed", []string{"a", "b", "c"}, []string{"a ASC", "b DESC", "c"}, 0, "(`a` > ? OR (`a` = ? AND `b` < ?) OR (`a` = ? AND `b` = ? AND `c` > ?))"},
+		{"pg row value", []string{"a", "b"}, nil, dialect.Postgres, `("a", "b") > (?, ?)`},
+		{"sqlserver", []string{"a", "b"}, nil, dialect.SQLServer, "([a] > ? OR ([a] = ? AND [b] > ?))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := make([]any, len(tt.names))
+			for i := range values {
+				values[i] = i + 1
+			}
+			obj := &SearchAfter{
+				SqlStr:   "SELECT id, a, b, c FROM man",
+				Table:    "man",
+				Names:    tt.names,
+				Values:   values,
+				OrderBys: tt.orderBys,
+				DbType:   tt.dbType,
+			}
+			if err := obj.init(); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := obj.getKeysetCond()
+			if got != tt.want {
+				t.Errorf("got: %s, want: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileCheckpointer(t *testing.T) {
+	ctx := context.Background()
+	cp := NewFileCheckpointer(filepath.Join(t.TempDir(), "checkpoint.json"))
+	values, err := cp.Load(ctx)
+	if err != nil || values != nil {
+		t.Fatal("no checkpoint, it should return nil, nil")
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	if err := cp.Save(ctx, []any{int32(1 << 30), uint64(1 << 60), "name", now, nil}); err != nil {
+		t.Fatal(err)
+	}
+	values, err = cp.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []any{int64(1 << 30), uint64(1 << 60), "name", now, nil}
+	if fmt.Sprint(values) != fmt.Sprint(want) {
+		t.Errorf("got: %v, want: %v", values, want)
+	}
+	if _, ok := values[0].(int64); !ok {
+		t.Errorf("int should load as int64, got: %T", values[0])
+	}
+}
+
+func TestParallelScan(t *testing.T) {
+	for i := 1; i <= 105; i++ {
+		InitTestMain(t)
+	}
+	var totalDst int32
+	_ = Count(db, "man", &totalDst, "1")
+
+	var (
+		mu      sync.Mutex
+		total   int
+		doneNum int
+		obj     = &ParallelScan{
+			SqlStr:     "select id,name from man",
+			Table:      "man",
+			Partitions: 4,
+			Workers:    2,
+			Size:       20,
+			Dest:       &ManCopy{},
+		}
+	)
+	obj.RowFn = func(partition int, _row any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		total++
+		return nil
+	}
+	obj.ProgressFn = func(progress PartitionProgress) {
+		if progress.Done {
+			mu.Lock()
+			doneNum++
+			mu.Unlock()
+			t.Logf("partition done: %+v", progress)
+		}
+	}
+	if err := obj.Scan(context.TODO(), db); err != nil {
+		t.Fatal(err)
+	}
+
+	if totalDst != int32(total) {
+		t.Error("it is no ok")
+	}
+	if doneNum != obj.Partitions {
+		t.Errorf("doneNum: %d, partitions: %d", doneNum, obj.Partitions)
+	}
+	t.Logf("total: %d, totalDst: %d", total, totalDst)
+}
+
+func TestSplitBoundaries(t *testing.T) {
+	tests := []struct {
+		min, max int64
+		n        int
+		want     string
+	}{
+		{min: 1, max: 103, n: 4, want: "[1 27 53 79 103]"},
+		{min: 1, max: 100, n: 1, want: "[1 100]"},
+		{min: 1, max: 3, n: 4, want: "[1 2 3]"},
+		{min: 5, max: 5, n: 4, want: "[5 5]"},
+	}
+	for _, v := range tests {
+		if got := fmt.Sprint(splitBoundaries(v.min, v.max, v.n)); got != v.want {
+			t.Errorf("splitBoundaries(%d, %d, %d) got: %s, want: %s", v.min, v.max, v.n, got, v.want)
+		}
+	}
+}
+
+func TestSearchAfterCursor(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := &SearchAfter{
+		Names:     []string{"id", "created_at"},
+		Values:    []any{int32(10), now},
+		OrderBys:  []string{"id ASC", "created_at DESC"},
+		CursorKey: []byte("secret"),
+	}
+	cursor, err := s.EncodeCursor()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("decode", func(t *testing.T) {
+		res := &SearchAfter{CursorKey: []byte("secret")}
+		if err := res.DecodeCursor(cursor); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(res.Names, res.OrderBys) != fmt.Sprint(s.Names, s.OrderBys) {
+			t.Errorf("got: %v %v, want: %v %v", res.Names, res.OrderBys, s.Names, s.OrderBys)
+		}
+		if v, ok := res.Values[0].(int64); !ok || v != 10 {
+			t.Errorf("values[0] got: %T %v", res.Values[0], res.Values[0])
+		}
+		if v, ok := res.Values[1].(time.Time); !ok || !v.Equal(now) {
+			t.Errorf("values[1] got: %T %v", res.Values[1], res.Values[1])
+		}
+	})
+
+	t.Run("sql not match", func(t *testing.T) {
+		s := &SearchAfter{SqlStr: "SELECT id FROM man WHERE age > 1", Names: []string{"id"}, Values: []any{1}, OrderBys: []string{"id ASC"}, CursorKey: []byte("secret")}
+		cursor, err := s.EncodeCursor()
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := &SearchAfter{SqlStr: "SELECT id FROM man WHERE age > 2", CursorKey: []byte("secret")}
+		if err := res.DecodeCursor(cursor); !errors.Is(err, InvalidCursorErr) {
+			t.Errorf("cursor of other sql should be invalid, err: %v", err)
+		}
+	})
+
+	t.Run("tamper", func(t *testing.T) {
+		other, _ := (&SearchAfter{Names: []string{"id"}, Values: []any{1000}, OrderBys: []string{"id ASC"}, CursorKey: []byte("other")}).EncodeCursor()
+		dataStr, _, _ := strings.Cut(other, ".")
+		_, signStr, _ := strings.Cut(cursor, ".")
+		for _, v := range []string{"", "abc", cursor + "x", dataStr + "." + signStr, other} {
+			res := &SearchAfter{CursorKey: []byte("secret")}
+			if err := res.DecodeCursor(v); !errors.Is(err, InvalidCursorErr) {
+				t.Errorf("cursor %q should be invalid, err: %v", v, err)
+			}
+		}
+	})
+}
+
+func TestSearchBeforeCond(t *testing.T) {
+	tests := []struct {
+		name         string
+		names        []string
+		orderBys     []string
+		wantCond     string
+		wantOrderBys string
+	}{
+		{"one col", []string{"id"}, nil, "`id` < ?", "[id DESC]"},
+		{"row value desc", []string{"a", "b"}, []string{"a DESC", "b desc"}, "(`a`, `b`) > (?, ?)", "[a ASC b ASC]"},
+		{"mixed", []string{"a", "b"}, []string{"a", "b DESC"}, "(`a` < ? OR (`a` = ? AND `b` > ?))", "[a DESC b ASC]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := make([]any, len(tt.names))
+			for i := range values {
+				values[i] = i + 1
+			}
+			obj := &SearchAfter{
+				SqlStr:   "SELECT id, a, b FROM man",
+				Table:    "man",
+				Names:    tt.names,
+				Values:   values,
+				OrderBys: tt.orderBys,
+				before:   true,
+			}
+			if err := obj.init(); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := obj.getKeysetCond(); got != tt.wantCond {
+				t.Errorf("cond got: %s, want: %s", got, tt.wantCond)
+			}
+			if got := fmt.Sprint(obj.getOrderBys()); got != tt.wantOrderBys {
+				t.Errorf("orderBys got: %s, want: %s", got, tt.wantOrderBys)
+			}
+		})
+	}
+}
+
+// mockManHandler 模拟 id 为 [1, maxId] 的 man 表的 min/max 和分页查询, 分页查询需要包含: `id` < 结束值(或 <=) 和 `id` > ?
+func mockManHandler(maxId int64) mockHandler {
+	var (
+		endRe   = regexp.MustCompile("`id` (<=?) (\\d+)")
+		limitRe = regexp.MustCompile(`LIMIT (\d+)`)
+	)
+	return func(query string, args []driver.Value) *mockResult {
+		if strings.HasPrefix(query, "SHOW COLUMNS") {
+			return mockManCols()
+		}
+		if strings.HasPrefix(query, "SELECT MIN") {
+			return &mockResult{cols: []string{"min", "max"}, rows: [][]driver.Value{{int64(1), maxId}}}
+		}
+
+		end, limit := maxId, 0
+		if m := endRe.FindStringSubmatch(query); m != nil {
+			end, _ = strconv.ParseInt(m[2], 10, 64)
+			if m[1] == "<" {
+				end--
+			}
+		}
+		if m := limitRe.FindStringSubmatch(query); m != nil {
+			limit, _ = strconv.Atoi(m[1])
+		}
+		res := &mockResult{cols: []string{"id", "name"}}
+		for id := args[0].(int64) + 1; id <= end && id <= maxId && len(res.rows) < limit; id++ {
+			res.rows = append(res.rows, []driver.Value{id, "name" + strconv.FormatInt(id, 10)})
+		}
+		return res
+	}
+}
+
+func TestParallelScanOfDbType(t *testing.T) {
+	mdb, mockDb := newMockDb(nil)
+	defer mdb.Close()
+
+	obj := &ParallelScan{
+		SqlStr:     builder.NewSelect(dialect.Postgres).Select("id", "name").From("man"),
+		Boundaries: []int64{1, 10},
+		Dest:       &ManCopy{},
+		RowFn:      func(int, any) error { return nil },
+	}
+	if err := obj.Scan(context.Background(), mdb); err != nil {
+		t.Fatal(err)
+	}
+	queries := mockDb.Queries() // 前面为查询表结构的 sql
+	want := `SELECT "id", "name" FROM man WHERE "id" <= 10 AND "id" > $1 ORDER BY id ASC LIMIT 10 OFFSET 0`
+	if got := queries[len(queries)-1]; got != want {
+		t.Errorf("query got: %s, want: %s", got, want)
+	}
+}
+
+// TestParallelScanOfSelectBuilder 多个分区共用同一个 *builder.Select, 需要使用 go test -race 执行
+func TestParallelScanOfSelectBuilder(t *testing.T) {
+	mdb, _ := newMockDb(mockManHandler(103))
+	defer mdb.Close()
+
+	var (
+		mu     sync.Mutex
+		idsMap = make(map[int32]int)
+		obj    = &ParallelScan{
+			SqlStr:     builder.NewSelect().Select("name").From("man").WhereCb(func(wb *builder.Where) { wb.Gt("age", 1) }),
+			Partitions: 8,
+			Workers:    4,
+			Size:       5,
+			Dest:       &ManCopy{},
+		}
+	)
+	obj.RowFn = func(partition int, _row any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		idsMap[_row.(*ManCopy).Id]++
+		return nil
+	}
+	if err := obj.Scan(context.TODO(), mdb); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(idsMap) != 103 {
+		t.Errorf("got: %d, want: 103", len(idsMap))
+	}
+	for id, num := range idsMap {
+		if num != 1 {
+			t.Errorf("id %d scanned %d times", id, num)
+		}
+	}
+}
+
+// TestSearchAfterNextPageConcurrent 多个请求共用同一个 SearchAfter, 需要使用 go test -race 执行
+func TestSearchAfterNextPageConcurrent(t *testing.T) {
+	mdb, _ := newMockDb(mockManHandler(23))
+	defer mdb.Close()
+
+	tpl := &SearchAfter{
+		SqlStr:    builder.NewSelect().Select("name").From("man").WhereCb(func(wb *builder.Where) { wb.Gt("age", 1) }),
+		Dest:      &ManCopy{},
+		CursorKey: []byte("secret"),
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var (
+				ids    []int32
+				cursor string
+			)
+			for {
+				page, err := tpl.NextPage(context.TODO(), mdb, cursor, 10)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				for _, row := range page.Rows {
+					ids = append(ids, row.(*ManCopy).Id)
+				}
+				if !page.HasNext {
+					break
+				}
+				cursor = page.NextCursor
+			}
+			if len(ids) != 23 || ids[0] != 1 || ids[22] != 23 {
+				t.Errorf("ids is not ok, got: %v", ids)
+			}
+		}()
+	}
+	wg.Wait()
+}
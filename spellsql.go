@@ -16,6 +16,7 @@ import (
 // 注: 该对象不进行后续维护, 后续主要维护 builder
 // 原因: v2 版本原本想去掉此对象, 但是考虑到 v1 版本的兼容性, 所以保留此对象
 type SqlStrObj struct {
+	engine        *Engine // 为 nil 时使用全局配置
 	ctx           context.Context
 	isPrintSqlLog bool            // 标记是否打印 生成的 sqlStr log
 	actionNum     internal.OpType // INSERT/DELETE/SELECT/UPDATE
@@ -155,7 +156,7 @@ func (s *SqlStrObj) GetSqlStr(title ...string) (sqlStr string) {
 		if argsLen > 0 {
 			defTitle = title[0]
 		}
-		sLog.Info(s.ctx, s.getLogTitle(defTitle)+sqlStr)
+		s.getLogger().Info(s.ctx, s.getLogTitle(defTitle)+sqlStr)
 	}
 	return
 }
@@ -179,11 +180,19 @@ func (s *SqlStrObj) GetTotalSqlStr(title ...string) (findSqlStr string) {
 		if argsLen > 0 {
 			defTitle = title[0]
 		}
-		sLog.Info(s.ctx, s.getLogTitle(defTitle)+findSqlStr)
+		s.getLogger().Info(s.ctx, s.getLogTitle(defTitle)+findSqlStr)
 	}
 	return
 }
 
+// getLogger 获取 logger
+func (s *SqlStrObj) getLogger() Logger {
+	if s.engine != nil {
+		return s.engine.logger
+	}
+	return sLog
+}
+
 // getLogTitle 获取 log title
 func (s *SqlStrObj) getLogTitle(title string) (finalTitle string) {
 	// 跳过当前
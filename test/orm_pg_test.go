@@ -202,6 +202,20 @@ func TestInsertForPg(t *testing.T) {
 	})
 }
 
+func TestInsertReturningForPg(t *testing.T) {
+	m1 := &Man{Name: "xue1234", Age: 18, Addr: "成都市"}
+	m2 := &Man{Name: "xue12345", Age: 19, Addr: "成都市"}
+	res, err := spellsql.NewTable(pgDb, "man").Insert(m1, m2).Returning().Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := res.RowsAffected()
+	lastId, _ := res.LastInsertId()
+	if !Equal(r, int64(2)) || m1.Id == 0 || !Equal(int64(m2.Id), lastId) || m1.Id >= m2.Id {
+		t.Error(NoEqErr)
+	}
+}
+
 func TestDeleteForPg(t *testing.T) {
 	m := Man{
 		Id: 9,
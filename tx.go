@@ -0,0 +1,141 @@
+package spellsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"gitee.com/xuesongtao/spellsql/v2/dialect"
+	"gitee.com/xuesongtao/spellsql/v2/internal"
+	"gitee.com/xuesongtao/spellsql/v2/utils"
+)
+
+// TxBeginner 可以开启事务的 db, 如: *sql.DB, *sql.Conn
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Tx 事务, 实现了 DBer, 可以直接用于 NewTable 等
+type Tx struct {
+	*sql.Tx
+	dbType  dialect.DbType
+	spIndex int // 保存点序号, 用于嵌套事务
+}
+
+var (
+	runningTxMu  sync.Mutex
+	runningTxMap = make(map[*sql.Tx]*runningTx) // 执行 WithTx 中的事务, key: *sql.Tx
+)
+
+// runningTx 执行 WithTx 中的事务, 同一个 *sql.Tx 嵌套调用时共用, 防止保存点重名
+type runningTx struct {
+	tx  *Tx
+	ref int
+}
+
+// WithTx 在事务中执行 fn, fn 返回 nil 时提交, 返回错误或 panic 时回滚
+// db 支持 TxBeginner/*Tx/*sql.Tx, 当 db 为 *Tx/*sql.Tx 时(嵌套调用)会通过保存点实现, fn 出错时只回滚到保存点
+// 嵌套调用时传入外层的 *sql.Tx(如: 闭包中捕获的)和传入 fn 的 tx 效果一样, 会共用保存点序号
+// dbType 用于获取保存点的语法, 默认 dialect.DefaultDbType, 嵌套调用时使用外层事务的 dbType
+// 注: fn 中的 panic 会被恢复并以 error 返回
+func WithTx(ctx context.Context, db DBer, fn func(tx DBer) error, dbType ...dialect.DbType) error {
+	dt := dialect.DefaultDbType
+	if len(dbType) > 0 {
+		dt = dbType[0]
+	}
+
+	switch v := db.(type) {
+	case *Tx:
+		return v.withSavepoint(ctx, fn)
+	case *sql.Tx:
+		tx, release := acquireTx(v, dt)
+		defer release()
+		return tx.withSavepoint(ctx, fn)
+	case TxBeginner:
+		sqlTx, err := v.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("begin tx is failed, err: %v", err)
+		}
+		tx, release := acquireTx(sqlTx, dt)
+		defer release()
+		if err := callTxFn(tx, fn); err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				return fmt.Errorf("%w; rollback is failed, err: %v", err, rbErr)
+			}
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit tx is failed, err: %v", err)
+		}
+		return nil
+	}
+	return internal.TxNotSupportErr
+}
+
+// acquireTx 获取 sqlTx 对应的 *Tx, sqlTx 已经在执行 WithTx 时返回同一个 *Tx
+// 使用完后需要调用返回的 release
+func acquireTx(sqlTx *sql.Tx, dt dialect.DbType) (tx *Tx, release func()) {
+	runningTxMu.Lock()
+	defer runningTxMu.Unlock()
+	rt, ok := runningTxMap[sqlTx]
+	if !ok {
+		rt = &runningTx{tx: &Tx{Tx: sqlTx, dbType: dt}}
+		runningTxMap[sqlTx] = rt
+	}
+	rt.ref++
+	return rt.tx, func() {
+		runningTxMu.Lock()
+		defer runningTxMu.Unlock()
+		if rt.ref--; rt.ref == 0 {
+			delete(runningTxMap, sqlTx)
+		}
+	}
+}
+
+// withSavepoint 通过保存点实现嵌套事务
+func (t *Tx) withSavepoint(ctx context.Context, fn func(tx DBer) error) error {
+	gd, err := dialect.GetDialect(t.dbType)
+	if err != nil {
+		return err
+	}
+
+	t.spIndex++
+	saveSql, rollbackSql, releaseSql := dialect.SavepointSqls(gd, "sp_"+utils.Int2Str(int64(t.spIndex)))
+	if _, err := t.ExecContext(ctx, saveSql); err != nil {
+		return fmt.Errorf("savepoint is failed, err: %v", err)
+	}
+
+	if err := callTxFn(t, fn); err != nil {
+		if _, rbErr := t.ExecContext(ctx, rollbackSql); rbErr != nil {
+			return fmt.Errorf("%w; rollback to savepoint is failed, err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	if releaseSql != "" {
+		if _, err := t.ExecContext(ctx, releaseSql); err != nil {
+			return fmt.Errorf("release savepoint is failed, err: %v", err)
+		}
+	}
+	return nil
+}
+
+// callTxFn 执行 fn, 将 panic 转为 error
+func callTxFn(tx *Tx, fn func(tx DBer) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("tx panic: %v", r)
+		}
+	}()
+	return fn(tx)
+}
+
+// WithTx 在事务中执行 fn, fn 中的 tx 和 e 共用配置和缓存, 用法同 WithTx
+func (e *Engine) WithTx(ctx context.Context, fn func(tx *Engine) error) error {
+	return WithTx(ctx, e.db, func(tx DBer) error {
+		txEngine := *e
+		txEngine.db = tx
+		return fn(&txEngine)
+	}, e.dbType)
+}
@@ -0,0 +1,113 @@
+package spellsql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"gitee.com/xuesongtao/spellsql/v2/test"
+)
+
+func TestWithTx(t *testing.T) {
+	ctx := context.Background()
+	t.Run("commit", func(t *testing.T) {
+		var total int
+		_ = Count(db, "man", &total, "name=?", "tx_commit")
+		err := WithTx(ctx, db, func(tx DBer) error {
+			_, err := NewTable(tx, "man").Insert(test.Man{Name: "tx_commit", Age: 1}).Exec()
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var newTotal int
+		_ = Count(db, "man", &newTotal, "name=?", "tx_commit")
+		if !test.Equal(newTotal, total+1) {
+			t.Error(test.NoEqErr)
+		}
+	})
+
+	t.Run("nested rollback", func(t *testing.T) {
+		innerErr := errors.New("inner rollback")
+		err := WithTx(ctx, db, func(tx DBer) error {
+			if _, err := NewTable(tx, "man").Insert(test.Man{Name: "tx_outer", Age: 1}).Exec(); err != nil {
+				return err
+			}
+			err := WithTx(ctx, tx, func(tx DBer) error {
+				if _, err := NewTable(tx, "man").Insert(test.Man{Name: "tx_inner", Age: 1}).Exec(); err != nil {
+					return err
+				}
+				return innerErr
+			})
+			if !errors.Is(err, innerErr) {
+				t.Error("inner err is not ok, err:", err)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var total int
+		_ = Count(db, "man", &total, "name=?", "tx_inner")
+		if !test.Equal(total, 0) {
+			t.Error(test.NoEqErr)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		err := WithTx(ctx, db, func(tx DBer) error {
+			panic("tx panic")
+		})
+		if err == nil {
+			t.Error("panic should return err")
+		}
+	})
+
+	t.Run("not support", func(t *testing.T) {
+		err := WithTx(ctx, nil, func(tx DBer) error { return nil })
+		if err == nil {
+			t.Error("it should be not support")
+		}
+	})
+}
+
+func TestWithTxOfRawTx(t *testing.T) {
+	ctx := context.Background()
+	mdb, mockDb := newMockDb(nil)
+	defer mdb.Close()
+
+	// 内层使用闭包中的 *sql.Tx, 和传入 fn 的 tx 共用保存点序号
+	err := WithTx(ctx, mdb, func(tx DBer) error {
+		rawTx := tx.(*Tx).Tx
+		return WithTx(ctx, rawTx, func(DBer) error {
+			err := WithTx(ctx, rawTx, func(DBer) error {
+				return errors.New("inner rollback")
+			})
+			if err == nil {
+				t.Error("inner should be failed")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"BEGIN",
+		"SAVEPOINT sp_1",
+		"SAVEPOINT sp_2",
+		"ROLLBACK TO SAVEPOINT sp_2",
+		"RELEASE SAVEPOINT sp_1",
+		"COMMIT",
+	}
+	if got := mockDb.Queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("queries got: %v, want: %v", got, want)
+	}
+	if len(runningTxMap) != 0 {
+		t.Errorf("running tx should be released, got: %d", len(runningTxMap))
+	}
+}
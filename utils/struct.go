@@ -60,3 +60,31 @@ func CheckImplementation(ty reflect.Type, targetInterface reflect.Type) bool {
 	}
 	return false
 }
+
+// FieldByIndex 通过索引路径获取结构体字段, 路径中有 nil 指针时返回 false
+func FieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// FieldByIndexAlloc 通过索引路径获取结构体字段, 路径中的 nil 指针会初始化, v 需要可寻址
+func FieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
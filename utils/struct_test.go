@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+type TestBase struct {
+	Id int
+}
+
+type testEmbed struct {
+	*TestBase
+	Name string
+}
+
+func TestFieldByIndex(t *testing.T) {
+	var obj testEmbed
+	v := reflect.ValueOf(&obj).Elem()
+	if _, ok := FieldByIndex(v, []int{0, 0}); ok {
+		t.Error("nil embedded ptr should return false")
+	}
+
+	FieldByIndexAlloc(v, []int{0, 0}).SetInt(1)
+	if obj.TestBase == nil || obj.Id != 1 {
+		t.Errorf("expected id to be 1, got %+v", obj.TestBase)
+	}
+
+	field, ok := FieldByIndex(v, []int{0, 0})
+	if !ok || field.Int() != 1 {
+		t.Errorf("expected id to be 1, got %v", field)
+	}
+}